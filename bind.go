@@ -0,0 +1,110 @@
+package summer
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Bind registers the type of proto as the implementation impl of the
+// interface iface points to, such as (*Cache)(nil). Both types are registered
+// like Register does. A dew whose class is the interface then selects its
+// implementation with its impl attribute:
+//
+//	<dew id="cache" class="cache.Cache" impl="redis" />
+//
+// A pointer to proto must satisfy the interface.
+func (c *Container) Bind(iface interface{}, impl string, proto interface{}) error {
+	ifaceType := reflect.TypeOf(iface)
+	if ifaceType == nil || ifaceType.Kind() != reflect.Ptr || ifaceType.Elem().Kind() != reflect.Interface {
+		return fmt.Errorf("expected a pointer to an interface but got %T", iface)
+	}
+	ifaceType = ifaceType.Elem()
+	implType := reflect.TypeOf(proto)
+	if impl == "" {
+		return fmt.Errorf("expected an implementation name for %s", implType)
+	}
+	if !reflect.PtrTo(implType).Implements(ifaceType) {
+		return fmt.Errorf("*%s doesn't satisfy %s", implType, ifaceType)
+	}
+	full, short := typeName(ifaceType)
+	if err := c.register(ifaceType, full, short); err != nil {
+		return err
+	}
+	if err := c.Register(proto); err != nil {
+		return err
+	}
+	return c.bind(ifaceType, impl, implType)
+}
+
+func (c *Container) bind(ifaceType reflect.Type, impl string, implType reflect.Type) error {
+	if existing, ok := c.bindings[ifaceType][impl]; ok && existing != implType {
+		return c.conflict(fmt.Errorf("implementation %s of %s is already bound to %s", impl, ifaceType, existing))
+	}
+	if c.bindings == nil {
+		c.bindings = make(map[reflect.Type]map[string]reflect.Type)
+	}
+	if c.bindings[ifaceType] == nil {
+		c.bindings[ifaceType] = make(map[string]reflect.Type)
+	}
+	c.bindings[ifaceType][impl] = implType
+	return nil
+}
+
+// Implementations returns the implementations bound to the interface class
+// name by implementation name, see Bind.
+func (c *Container) Implementations(class string) map[string]reflect.Type {
+	impls := make(map[string]reflect.Type)
+	if t := c.GetType(class); t != nil {
+		for impl, implType := range c.bindings[t] {
+			impls[impl] = implType
+		}
+	}
+	return impls
+}
+
+// classType returns the type a dew instantiates: its class or, for an
+// interface class, the implementation it selects.
+func (c *Container) classType(d *DewDefinition) (reflect.Type, error) {
+	t := c.GetType(d.Class)
+	if t == nil {
+		return nil, c.classError(d.Class)
+	}
+	if t.Kind() != reflect.Interface {
+		if d.Impl != "" {
+			return nil, fmt.Errorf("class %s isn't an interface, it has no implementation %s", d.Class, d.Impl)
+		}
+		return t, nil
+	}
+	impls := sortedKeys(c.bindings[t])
+	if d.Impl == "" {
+		return nil, fmt.Errorf("interface %s expects an impl, one of %s", d.Class, strings.Join(impls, ", "))
+	}
+	impl, ok := c.bindings[t][d.Impl]
+	if !ok {
+		return nil, fmt.Errorf("interface %s has no implementation %s, expected one of %s", d.Class, d.Impl, strings.Join(impls, ", "))
+	}
+	if !reflect.PtrTo(impl).Implements(t) {
+		return nil, fmt.Errorf("implementation %s (*%s) doesn't satisfy %s", d.Impl, impl, t)
+	}
+	return impl, nil
+}
+
+// mergeBindings binds the implementations other binds, see Merge.
+func (c *Container) mergeBindings(other *Container) []error {
+	ifaces := make([]reflect.Type, 0, len(other.bindings))
+	for t := range other.bindings {
+		ifaces = append(ifaces, t)
+	}
+	sort.Slice(ifaces, func(i, j int) bool { return ifaces[i].String() < ifaces[j].String() })
+	var errs []error
+	for _, t := range ifaces {
+		for _, impl := range sortedKeys(other.bindings[t]) {
+			if err := c.bind(t, impl, other.bindings[t][impl]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
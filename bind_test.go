@@ -0,0 +1,92 @@
+package summer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type BindCache interface {
+	Get(key string) string
+}
+
+type RedisCache struct {
+	Addr string
+}
+
+func (c *RedisCache) Get(key string) string { return "redis:" + key }
+
+type MemoryCache struct{}
+
+func (c *MemoryCache) Get(key string) string { return "memory:" + key }
+
+type BindUser struct {
+	Cache BindCache
+}
+
+func newBindContainer(t *testing.T) *Container {
+	con := new(Container)
+	con.Register(BindUser{})
+	if err := con.Bind((*BindCache)(nil), "redis", RedisCache{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.Bind((*BindCache)(nil), "memory", MemoryCache{}); err != nil {
+		t.Fatal(err)
+	}
+	return con
+}
+
+func TestContainer_Bind(t *testing.T) {
+	con := newBindContainer(t)
+	config := []byte(`
+<rain>
+<dew id="cache" class="summer.BindCache" impl="redis">
+	<vapor name="Addr" value="localhost:6379" />
+</dew>
+<dew id="user" class="summer.BindUser">
+	<vapor name="Cache" dew="cache" />
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := app.GetDewByName("user").Value.(*BindUser).Cache
+	if cache.Get("a") != "redis:a" || cache.(*RedisCache).Addr != "localhost:6379" {
+		t.Fatalf("unexpected %+v", cache)
+	}
+
+	impls := con.Implementations("summer.BindCache")
+	if len(impls) != 2 || impls["redis"] != reflect.TypeOf(RedisCache{}) || impls["memory"] != reflect.TypeOf(MemoryCache{}) {
+		t.Fatalf("unexpected %v", impls)
+	}
+}
+
+func TestContainer_BindErrors(t *testing.T) {
+	con := newBindContainer(t)
+	if err := con.Bind(BindCache(nil), "redis", RedisCache{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := con.Bind((*BindCache)(nil), "user", BindUser{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := con.Bind((*BindCache)(nil), "redis", MemoryCache{}); err == nil || len(con.Conflicts()) != 1 {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	cases := map[string]string{
+		`<dew class="summer.BindCache" />`:                  "interface summer.BindCache expects an impl, one of memory, redis",
+		`<dew class="summer.BindCache" impl="memcached" />`: "interface summer.BindCache has no implementation memcached, expected one of memory, redis",
+		`<dew class="summer.BindUser" impl="redis" />`:      "class summer.BindUser isn't an interface, it has no implementation redis",
+	}
+	for dew, msg := range cases {
+		config := []byte("<rain>" + dew + "</rain>")
+		if _, err := con.XMLConfigurationContainer(config, nil); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("expected %q, got %v", msg, err)
+		}
+		if err := con.ValidateXML(config); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("expected %q, got %v", msg, err)
+		}
+	}
+}
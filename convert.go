@@ -0,0 +1,52 @@
+package summer
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Converter converts a vapor value into a value of the type it is registered
+// for, or a pointer to one.
+type Converter func(value string) (interface{}, error)
+
+type converters map[reflect.Type]Converter
+
+// RegisterConverter registers a Converter for the values of type t. It takes
+// precedence over the built-in conversions, for fields as well as for the
+// elements and keys of lists, arrays and maps.
+func (c *Container) RegisterConverter(t reflect.Type, fn Converter) {
+	if c.converters == nil {
+		c.converters = make(converters)
+	}
+	c.converters[t] = fn
+}
+
+// convert sets v with the converter registered for its type, if any.
+func (cv converters) convert(v reflect.Value, value string) (bool, error) {
+	fn, ok := cv[v.Type()]
+	if !ok {
+		return false, nil
+	}
+	result, err := fn(value)
+	if err != nil {
+		return true, err
+	}
+	if result == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return true, nil
+	}
+	rv := reflect.ValueOf(result)
+	switch {
+	case rv.Type().AssignableTo(v.Type()):
+		v.Set(rv)
+	case rv.Kind() == reflect.Ptr && rv.Type().Elem().AssignableTo(v.Type()):
+		if rv.IsNil() {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(rv.Elem())
+		}
+	default:
+		return true, fmt.Errorf("converter for type %s returned a %s", v.Type(), rv.Type())
+	}
+	return true, nil
+}
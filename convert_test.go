@@ -0,0 +1,111 @@
+package summer
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+type test_level int
+
+type ConvertTest struct {
+	Pattern  *regexp.Regexp
+	Big      big.Int
+	Level    test_level
+	Levels   []test_level
+	Names    map[test_level]string
+	Answers  map[test_level]Answerable
+	Patterns [2]*regexp.Regexp
+}
+
+func registerTestConverters(con *Container) {
+	con.RegisterConverter(reflect.TypeOf(&regexp.Regexp{}), func(value string) (interface{}, error) {
+		return regexp.Compile(value)
+	})
+	con.RegisterConverter(reflect.TypeOf(big.Int{}), func(value string) (interface{}, error) {
+		n, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid big.Int %s", value)
+		}
+		return n, nil
+	})
+	con.RegisterConverter(reflect.TypeOf(test_level(0)), func(value string) (interface{}, error) {
+		switch value {
+		case "debug":
+			return test_level(0), nil
+		case "info":
+			return test_level(1), nil
+		case "error":
+			return test_level(2), nil
+		}
+		return nil, fmt.Errorf("invalid level %s", value)
+	})
+}
+
+func TestContainer_RegisterConverter(t *testing.T) {
+	con := new(Container)
+	con.Register(ConvertTest{})
+	con.Register(StructAnswer{})
+	registerTestConverters(con)
+	config := []byte(`
+<rain>
+<dew id="answer" class="summer.StructAnswer">
+<vapor name="Ans" value="666" />
+</dew>
+<dew id="test" class="summer.ConvertTest">
+<vapor name="Pattern" value="^a+$" />
+<vapor name="Big" value="123456789012345678901234567890" />
+<vapor name="Level" value="info" />
+<vapor name="Levels">
+	<vapor value="debug" />
+	<vapor value="error" />
+</vapor>
+<vapor name="Names">
+	<vapor name="error" value="oops" />
+</vapor>
+<vapor name="Answers">
+	<vapor name="info" dew="answer" />
+</vapor>
+<vapor name="Patterns">
+	<vapor value="a" />
+	<vapor value="b" />
+</vapor>
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := app.GetDewByName("test").Value.(*ConvertTest)
+	if !test.Pattern.MatchString("aaa") || test.Big.String() != "123456789012345678901234567890" {
+		t.Fatalf("unexpected values %+v", test)
+	}
+	if test.Level != 1 || test.Levels[1] != 2 || test.Names[2] != "oops" {
+		t.Fatalf("unexpected levels %+v", test)
+	}
+	if test.Answers[1].Answer() != 666 || !test.Patterns[1].MatchString("b") {
+		t.Fatalf("unexpected values %+v", test)
+	}
+}
+
+func TestContainer_RegisterConverter_Bad(t *testing.T) {
+	con := new(Container)
+	con.Register(ConvertTest{})
+	registerTestConverters(con)
+	con.RegisterConverter(reflect.TypeOf(big.Int{}), func(value string) (interface{}, error) {
+		return value, nil
+	})
+	for _, vapor := range []string{
+		`<vapor name="Pattern" value="(" />`,
+		`<vapor name="Level" value="warn" />`,
+		`<vapor name="Big" value="1" />`,
+	} {
+		config := []byte(`<rain><dew id="test" class="summer.ConvertTest">` + vapor + `</dew></rain>`)
+		if app, err := con.XMLConfigurationContainer(config, nil); app != nil || err == nil {
+			t.Fatalf("expected an error for %s", vapor)
+		}
+	}
+}
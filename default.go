@@ -0,0 +1,54 @@
+package summer
+
+import "reflect"
+
+// DefaultContainer is the Container the package-level functions register
+// into. Libraries can register their types from their init functions, so that
+// main only loads the configuration:
+//
+//	func init() {
+//		summer.Register(Store{})
+//	}
+//
+// Conflicting registrations are reported by Conflicts.
+var DefaultContainer = new(Container)
+
+// Register registers the type of proto into DefaultContainer, see
+// Container.Register.
+func Register(proto interface{}) error {
+	return DefaultContainer.Register(proto)
+}
+
+// RegisterAs registers the type of proto as name into DefaultContainer, see
+// Container.RegisterAs.
+func RegisterAs(name string, proto interface{}) error {
+	return DefaultContainer.RegisterAs(name, proto)
+}
+
+// Alias adds an alias of a class of DefaultContainer, see Container.Alias.
+func Alias(alias, name string) error {
+	return DefaultContainer.Alias(alias, name)
+}
+
+// RegisterFactory registers fn as the class name into DefaultContainer, see
+// Container.RegisterFactory.
+func RegisterFactory(name string, fn interface{}) error {
+	return DefaultContainer.RegisterFactory(name, fn)
+}
+
+// RegisterConverter registers a Converter for the values of type t into
+// DefaultContainer, see Container.RegisterConverter.
+func RegisterConverter(t reflect.Type, fn Converter) {
+	DefaultContainer.RegisterConverter(t, fn)
+}
+
+// Bind binds an implementation of an interface into DefaultContainer, see
+// Container.Bind.
+func Bind(iface interface{}, impl string, proto interface{}) error {
+	return DefaultContainer.Bind(iface, impl, proto)
+}
+
+// Conflicts returns the registration conflicts of DefaultContainer.
+func Conflicts() []error {
+	return DefaultContainer.Conflicts()
+}
@@ -0,0 +1,28 @@
+package summer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDefaultContainer(t *testing.T) {
+	saved := DefaultContainer
+	defer func() { DefaultContainer = saved }()
+	DefaultContainer = new(Container)
+
+	if err := Register(StructAnswer{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterAs("server", test_server{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Alias("server", "summer.StructAnswer"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if DefaultContainer.GetType("server") != reflect.TypeOf(test_server{}) {
+		t.Fatal("server isn't registered")
+	}
+	if len(Conflicts()) != 1 {
+		t.Fatalf("unexpected conflicts %v", Conflicts())
+	}
+}
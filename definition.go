@@ -0,0 +1,112 @@
+package summer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rain is the format independent description of a configuration. The XML,
+// YAML and JSON loaders decode into it, and it can be built by hand and
+// passed to Container.Build.
+type Rain struct {
+	Dews []DewDefinition
+}
+
+// DewDefinition describes a dew: the registered class to instantiate and the
+// vapors to inject into its fields.
+type DewDefinition struct {
+	Id     string // Optional, unnamed dews are injected by type
+	Class  string // Registered type name, full or short, or alias, e.g. summer.StructAnswer
+	Impl   string // Implementation of an interface class, see Container.Bind
+	Vapors []VaporDefinition
+	// Profile expressions such as "dev,!prod". The dew is only used when every
+	// expression matches the active profiles, see WithProfiles.
+	Profiles   []string
+	Order      int      // Position in auto slices, before the id
+	Primary    bool     // Wins ties between dews assignable to a field
+	Qualifiers []string // Labels selecting the dew, see VaporDefinition.Qualifier
+	Position            // Optional, where the dew is defined
+}
+
+// active reports whether every profile expression of the dew matches. An
+// expression is a comma separated list of profiles, any of which may be
+// negated with a leading "!"; it matches when one of them does.
+func (d *DewDefinition) active(profiles map[string]bool) bool {
+	for _, expr := range d.Profiles {
+		matched := strings.TrimSpace(expr) == ""
+		for _, p := range strings.Split(expr, ",") {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			if strings.HasPrefix(p, "!") {
+				matched = !profiles[strings.TrimSpace(p[1:])]
+			} else {
+				matched = profiles[p]
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// VaporDefinition describes what to inject into a field of a dew: a named
+// dew, an unnamed dew (Auto), a const Value or the elements of a list, array
+// or map field.
+type VaporDefinition struct {
+	Name      string            // Field name, or the key of a map element
+	Dew       string            // Id of the dew to inject
+	Value     string            // Const value
+	Private   bool              // Inject a new dew
+	Auto      bool              // Inject an unnamed dew
+	Optional  bool              // Leave the field alone when the dew to inject is missing
+	Named     bool              // Also collect the named dews into an auto slice
+	Qualifier string            // Inject the dew carrying this label
+	List      []VaporDefinition // Elements of a list, array or map field
+	Position                    // Optional, where the vapor is defined
+}
+
+// Position locates a definition in a configuration. Line and Column start at
+// 1 and are zero when unknown.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	switch {
+	case p.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", displayFile(p.File), p.Line, p.Column)
+	case p.Line > 0:
+		return fmt.Sprintf("%s:%d", displayFile(p.File), p.Line)
+	case p.File != "":
+		return p.File
+	}
+	return ""
+}
+
+// checkDuplicates reports dews sharing an id, locating both of them.
+func checkDuplicates(dews []DewDefinition) error {
+	seen := make(map[string]*DewDefinition)
+	for i := range dews {
+		d := &dews[i]
+		if d.Id == "" {
+			continue
+		}
+		if first, ok := seen[d.Id]; ok {
+			where := first.Position.String()
+			if where == "" {
+				where = "another dew"
+			}
+			return dewError(d, fmt.Errorf("id %s is already defined at %s", d.Id, where))
+		}
+		seen[d.Id] = d
+	}
+	return nil
+}
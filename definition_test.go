@@ -0,0 +1,84 @@
+package summer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContainer_Build(t *testing.T) {
+	con := new(Container)
+	con.Register(AnswerSpeaker{})
+	con.Register(StructAnswer{})
+	con.Register(StructInlineDewTest{})
+	def := &Rain{
+		Dews: []DewDefinition{
+			{
+				Id:     "test",
+				Class:  "summer.StructAnswer",
+				Vapors: []VaporDefinition{{Name: "Ans", Value: "666"}},
+			},
+			{
+				Id:     "checker",
+				Class:  "summer.AnswerSpeaker",
+				Vapors: []VaporDefinition{{Name: "Answer", Dew: "test"}},
+			},
+			{
+				Id:    "list",
+				Class: "summer.StructInlineDewTest",
+				Vapors: []VaporDefinition{
+					{Name: "List", List: []VaporDefinition{{Dew: "test"}}},
+					{Name: "Map", List: []VaporDefinition{{Name: "key", Dew: "test"}}},
+				},
+			},
+		},
+	}
+	app, err := con.Build(def, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Start(context.Background()) != nil {
+		t.Fail()
+	}
+	list := app.GetDewByName("list").Value.(*StructInlineDewTest)
+	if list.List[0].Answer() != 666 || list.Map["key"].Answer() != 666 {
+		t.Fail()
+	}
+	app.Stop(context.Background())
+}
+
+func TestContainer_Build_BadClass(t *testing.T) {
+	con := new(Container)
+	def := &Rain{Dews: []DewDefinition{{Id: "test", Class: "summer.StructAnswer"}}}
+	app, err := con.Build(def, nil)
+	if app != nil || err == nil {
+		t.Fail()
+	}
+}
+
+func TestDewDefinition_Active(t *testing.T) {
+	cases := []struct {
+		Profiles []string
+		Active   []string
+		Expected bool
+	}{
+		{Expected: true},
+		{Profiles: []string{"dev"}, Expected: false},
+		{Profiles: []string{"dev"}, Active: []string{"dev"}, Expected: true},
+		{Profiles: []string{"!prod"}, Expected: true},
+		{Profiles: []string{"!prod"}, Active: []string{"prod"}, Expected: false},
+		{Profiles: []string{"dev,!prod"}, Active: []string{"prod"}, Expected: false},
+		{Profiles: []string{"dev, !prod"}, Active: []string{"dev", "prod"}, Expected: true},
+		{Profiles: []string{"dev,staging", "!eu"}, Active: []string{"staging"}, Expected: true},
+		{Profiles: []string{"dev,staging", "!eu"}, Active: []string{"staging", "eu"}, Expected: false},
+	}
+	for _, e := range cases {
+		active := make(map[string]bool)
+		for _, p := range e.Active {
+			active[p] = true
+		}
+		d := DewDefinition{Profiles: e.Profiles}
+		if d.active(active) != e.Expected {
+			t.Fatalf("unexpected result for %+v", e)
+		}
+	}
+}
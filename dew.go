@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
+	"sort"
 )
 
 // Logger allows for simple logging as inject traverses and populates the
@@ -24,8 +25,12 @@ type VaporOption struct {
 
 // Field option
 type Option struct {
-	Name  string
-	Vapor []VaporOption
+	Name      string
+	Vapor     []VaporOption
+	Private   bool   // If true, a new Dew is always created for the field
+	Optional  bool   // If true, the field is left alone when no Dew matches
+	Named     bool   // If true, an auto slice also collects the named Dews
+	Qualifier string // If set, only the Dews carrying this label are assignable
 }
 
 // Dependence type
@@ -34,16 +39,24 @@ type Dependence struct {
 	Object *Dew
 }
 
-// An Dew in the Graph.
+// An Dew in the Graph. When it's provided, the vapor tags of its fields add
+// the Options it doesn't set itself, and their const values are assigned to
+// the fields still holding their zero value.
 type Dew struct {
 	Value        interface{}
 	Name         string            // Optional
 	Complete     bool              // If true, the Value will be considered complete
+	Order        int               // Position in the auto slices, before the name
+	Primary      bool              // If true, the Value wins ties between assignable Dews
+	Qualifiers   []string          // Labels selecting the Dew, see Option.Qualifier
 	Options      map[string]Option // The field names that named dependency were injected into
 	Dependencies []*Dependence     // Dew's Dependencies
 	reflectType  reflect.Type
 	reflectValue reflect.Value
 	created      bool // If true, the Dew was created by us
+	private      bool // If true, the Dew was created for a private field only
+	valued       bool // If true, the const vapor tags were already assigned
+	populated    bool // If true, the Dew was populated for a function, see populateEarly
 }
 
 // String representation suitable for human consumption.
@@ -56,6 +69,15 @@ func (o *Dew) String() string {
 	return buf.String()
 }
 
+func (o *Dew) hasQualifier(qualifier string) bool {
+	for _, q := range o.Qualifiers {
+		if q == qualifier {
+			return true
+		}
+	}
+	return false
+}
+
 func (o *Dew) addDep(field string, dep *Dew) {
 	if o.Dependencies == nil {
 		o.Dependencies = make([]*Dependence, 0)
@@ -65,11 +87,14 @@ func (o *Dew) addDep(field string, dep *Dew) {
 
 // The Graph of Objects.
 type Graph struct {
-	Logger      Logger // Optional, will trigger debug logging.
-	unnamed     []*Dew
-	unnamedType map[reflect.Type]bool
-	named       map[string]*Dew
-	started     []*Dew
+	Logger          Logger                     // Optional, will trigger debug logging.
+	PropertySources []PropertySource           // Optional, the environment by default.
+	Converters      map[reflect.Type]Converter // Optional, see Container.RegisterConverter.
+	unnamed         []*Dew
+	unnamedType     map[reflect.Type]bool
+	named           map[string]*Dew
+	started         []*Dew
+	factories       []*factory
 }
 
 // Provide objects to the Graph. The Dew documentation describes
@@ -86,6 +111,23 @@ func (g *Graph) Provide(objects ...*Dew) error {
 			)
 		}
 
+		// The vapor tags declare the options the Dew doesn't set itself.
+		if isStructPtr(o.reflectType) {
+			options, err := tagOptions(o.reflectType.Elem())
+			if err != nil {
+				return err
+			}
+			for name, option := range o.Options {
+				options[name] = option
+			}
+			o.Options = options
+			if !o.valued {
+				if err := g.tagValues(o); err != nil {
+					return err
+				}
+			}
+		}
+
 		if o.Name == "" {
 			if !isStructPtr(o.reflectType) {
 				return fmt.Errorf(
@@ -100,13 +142,15 @@ func (g *Graph) Provide(objects ...*Dew) error {
 				g.unnamedType = make(map[reflect.Type]bool)
 			}
 
-			if g.unnamedType[o.reflectType] {
-				return fmt.Errorf(
-					"provided two unnamed instances of type *%s",
-					o.reflectType.Elem().String(),
-				)
+			if !o.private {
+				if g.unnamedType[o.reflectType] {
+					return fmt.Errorf(
+						"provided two unnamed instances of type *%s",
+						o.reflectType.Elem().String(),
+					)
+				}
+				g.unnamedType[o.reflectType] = true
 			}
-			g.unnamedType[o.reflectType] = true
 			g.unnamed = append(g.unnamed, o)
 		} else {
 			if g.named == nil {
@@ -130,10 +174,69 @@ func (g *Graph) Provide(objects ...*Dew) error {
 	return nil
 }
 
+// properties returns the property sources of the Graph: its PropertySources,
+// or the environment without any .env file if it has none.
+func (g *Graph) properties() properties {
+	if g.PropertySources == nil {
+		return properties{EnvSource{}}
+	}
+	return properties(g.PropertySources)
+}
+
+// tagValues assigns the const values set by the vapor tags of o to its fields
+// still holding their zero value. The <class>.<field> property overrides them,
+// the class being the short name of the type of o.
+func (g *Graph) tagValues(o *Dew) error {
+	t := o.reflectType.Elem()
+	tags, err := vaporTags(t)
+	if err != nil {
+		return err
+	}
+	_, class := typeName(t)
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		tag, ok := tags[name]
+		if !ok || !tag.isConst() || !o.reflectValue.Elem().Field(i).IsZero() {
+			continue
+		}
+		value, err := g.properties().tagValue(class, name, tag)
+		if err == nil {
+			err = converters(g.Converters).setStructField(o.Value, name, value)
+		}
+		if err != nil {
+			return fmt.Errorf("field %s in type %s: %w", name, o.reflectType, err)
+		}
+		if g.Logger != nil {
+			g.Logger.Debugf("assigned %s to field %s in %s", value, name, o)
+		}
+	}
+	return nil
+}
+
+// populateError is an error populating field of dew, so that Build can
+// locate it in the configuration. It reads like the error it wraps.
+type populateError struct {
+	dew   *Dew
+	field string
+	err   error
+}
+
+func (e *populateError) Error() string {
+	return e.err.Error()
+}
+
+func (e *populateError) Unwrap() error {
+	return e.err
+}
+
 // Populate the incomplete Objects.
 func (g *Graph) Populate() error {
+	if err := g.callFactories(); err != nil {
+		return err
+	}
+
 	for _, o := range g.named {
-		if o.Complete {
+		if o.Complete || o.populated {
 			continue
 		}
 
@@ -153,7 +256,7 @@ func (g *Graph) Populate() error {
 		o := g.unnamed[i]
 		i++
 
-		if o.Complete {
+		if o.Complete || o.populated {
 			continue
 		}
 
@@ -165,7 +268,7 @@ func (g *Graph) Populate() error {
 	// A Second pass handles injecting Interface values to ensure we have created
 	// all concrete types first.
 	for _, o := range g.unnamed {
-		if o.Complete {
+		if o.Complete || o.populated {
 			continue
 		}
 
@@ -175,7 +278,7 @@ func (g *Graph) Populate() error {
 	}
 
 	for _, o := range g.named {
-		if o.Complete {
+		if o.Complete || o.populated {
 			continue
 		}
 
@@ -187,17 +290,24 @@ func (g *Graph) Populate() error {
 	return nil
 }
 
-func (g *Graph) populateExplicit(o *Dew) error {
+func (g *Graph) populateExplicit(o *Dew) (err error) {
 	// Ignore named value types.
 	if o.Name != "" && !isStructPtr(o.reflectType) {
 		return nil
 	}
 
+	var fieldName string
+	defer func() {
+		if err != nil {
+			err = &populateError{dew: o, field: fieldName, err: err}
+		}
+	}()
+
 StructLoop:
 	for i := 0; i < o.reflectValue.Elem().NumField(); i++ {
 		field := o.reflectValue.Elem().Field(i)
 		fieldType := field.Type()
-		fieldName := o.reflectType.Elem().Field(i).Name
+		fieldName = o.reflectType.Elem().Field(i).Name
 		option, ok := o.Options[fieldName]
 		if !ok {
 			continue
@@ -220,6 +330,17 @@ StructLoop:
 		// Named injects must have been explicitly provided.
 		if option.Name != "" {
 			existing := g.named[option.Name]
+			if existing == nil && option.Optional {
+				if g.Logger != nil {
+					g.Logger.Debugf(
+						"skipped optional field %s in %s: did not find object named %s",
+						o.reflectType.Elem().Field(i).Name,
+						o,
+						option.Name,
+					)
+				}
+				continue
+			}
 			if existing == nil {
 				return fmt.Errorf(
 					"did not find object named %s required by field %s in type %s",
@@ -255,6 +376,19 @@ StructLoop:
 
 		// Interface injection is handled in a second pass.
 		if fieldType.Kind() == reflect.Interface {
+			if option.Private {
+				return fmt.Errorf(
+					"found private inject option on interface field %s in type %s",
+					o.reflectType.Elem().Field(i).Name,
+					o.reflectType,
+				)
+			}
+			continue
+		}
+
+		// Auto slices and maps collect every assignable Dew in a second pass,
+		// once all of them are created.
+		if (fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Map) && option.Vapor == nil && option.Name == "" {
 			continue
 		}
 
@@ -312,7 +446,7 @@ StructLoop:
 			newMap := reflect.MakeMap(fieldType)
 			for _, vapor := range option.Vapor {
 				valueKey := reflect.New(fieldType.Key()).Elem()
-				if err := setFieldWithString(valueKey, vapor.Name); err != nil {
+				if err := converters(g.Converters).setField(valueKey, vapor.Name, ""); err != nil {
 					return err
 				}
 				existing := g.named[vapor.Dew]
@@ -367,10 +501,30 @@ StructLoop:
 			)
 		}
 
+		// Optional injects don't create anything, unless they're private, so
+		// they're handled in the second pass once every Dew is created.
+		if option.Optional && !option.Private {
+			continue
+		}
+
 		// Unless it's a private inject, we'll look for an existing instance of the
 		// same type.
-		for _, existing := range g.unnamed {
-			if existing.reflectType.AssignableTo(fieldType) {
+		if !option.Private {
+			candidates := preferPrimary(g.assignable(fieldType, option))
+			if len(candidates) > 1 {
+				return fmt.Errorf(
+					"found two assignable values for field %s in type %s. one type "+
+						"%s with value %v and another type %s with value %v",
+					o.reflectType.Elem().Field(i).Name,
+					o.reflectType,
+					candidates[0].reflectType,
+					candidates[0].Value,
+					candidates[1].reflectType,
+					candidates[1].reflectValue,
+				)
+			}
+			if len(candidates) == 1 {
+				existing := candidates[0]
 				field.Set(reflect.ValueOf(existing.Value))
 				if g.Logger != nil {
 					g.Logger.Debugf(
@@ -385,10 +539,21 @@ StructLoop:
 			}
 		}
 
+		// Created Dews carry no qualifier.
+		if option.Qualifier != "" && !option.Private {
+			return fmt.Errorf(
+				"found no assignable value qualified %s for field %s in type %s",
+				option.Qualifier,
+				o.reflectType.Elem().Field(i).Name,
+				o.reflectType,
+			)
+		}
+
 		newValue := reflect.New(fieldType.Elem())
 		newObject := &Dew{
 			Value:   newValue.Interface(),
 			created: true,
+			private: option.Private,
 		}
 
 		// Add the newly ceated object to the known set of objects.
@@ -412,68 +577,97 @@ StructLoop:
 	return nil
 }
 
-func (g *Graph) populateUnnamedInterface(o *Dew) error {
+func (g *Graph) populateUnnamedInterface(o *Dew) (err error) {
 	// Ignore named value types.
 	if o.Name != "" && !isStructPtr(o.reflectType) {
 		return nil
 	}
 
+	var fieldName string
+	defer func() {
+		if err != nil {
+			err = &populateError{dew: o, field: fieldName, err: err}
+		}
+	}()
+
 	for i := 0; i < o.reflectValue.Elem().NumField(); i++ {
 		field := o.reflectValue.Elem().Field(i)
 		fieldType := field.Type()
-		fieldName := o.reflectType.Elem().Field(i).Name
+		fieldName = o.reflectType.Elem().Field(i).Name
 		option, ok := o.Options[fieldName]
 		if !ok {
 			continue
 		}
 
-		// We only handle interface injection here. Other cases including errors
-		// are handled in the first pass when we inject pointers.
-		if fieldType.Kind() != reflect.Interface {
+		// Don't overwrite existing values.
+		if !isNilOrZero(field, fieldType) {
 			continue
 		}
 
-		// Don't overwrite existing values.
-		if !isNilOrZero(field, fieldType) {
+		if (fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Map) && option.Vapor == nil && option.Name == "" {
+			if err := g.populateAll(o, i, option); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// We only handle interface and optional pointer injection here. Other
+		// cases including errors are handled in the first pass when we inject
+		// pointers.
+		if fieldType.Kind() != reflect.Interface && !(isStructPtr(fieldType) && option.Optional && !option.Private) {
 			continue
 		}
 
-		// Named injects must have already been handled in populateExplicit.
+		// Named injects must have already been handled in populateExplicit,
+		// optional ones being left alone when their Dew is missing.
+		if option.Name != "" && option.Optional {
+			continue
+		}
 		if option.Name != "" {
 			panic(fmt.Sprintf("unhandled named instance with name %s", option.Name))
 		}
 
-		// Find one, and only one assignable value for the field.
+		// Find one, and only one assignable value for the field. A single primary
+		// value wins ties.
+		candidates := preferPrimary(g.assignable(fieldType, option))
+		if len(candidates) > 1 {
+			return fmt.Errorf(
+				"found two assignable values for field %s in type %s. one type "+
+					"%s with value %v and another type %s with value %v",
+				o.reflectType.Elem().Field(i).Name,
+				o.reflectType,
+				candidates[0].reflectType,
+				candidates[0].Value,
+				candidates[1].reflectType,
+				candidates[1].reflectValue,
+			)
+		}
 		var found *Dew
-		for _, existing := range g.unnamed {
-			if existing.reflectType.AssignableTo(fieldType) {
-				if found != nil {
-					return fmt.Errorf(
-						"found two assignable values for field %s in type %s. one type "+
-							"%s with value %v and another type %s with value %v",
-						o.reflectType.Elem().Field(i).Name,
-						o.reflectType,
-						found.reflectType,
-						found.Value,
-						existing.reflectType,
-						existing.reflectValue,
-					)
-				}
-				found = existing
-				field.Set(reflect.ValueOf(existing.Value))
-				if g.Logger != nil {
-					g.Logger.Debugf(
-						"assigned existing %s to interface field %s in %s",
-						existing,
-						o.reflectType.Elem().Field(i).Name,
-						o,
-					)
-				}
-				o.addDep(fieldName, existing)
+		if len(candidates) == 1 {
+			found = candidates[0]
+			field.Set(reflect.ValueOf(found.Value))
+			if g.Logger != nil {
+				g.Logger.Debugf(
+					"assigned existing %s to interface field %s in %s",
+					found,
+					o.reflectType.Elem().Field(i).Name,
+					o,
+				)
 			}
+			o.addDep(fieldName, found)
 		}
 
 		// If we didn't find an assignable value, we're missing something.
+		if found == nil && option.Optional {
+			if g.Logger != nil {
+				g.Logger.Debugf(
+					"skipped optional field %s in %s: found no assignable value",
+					o.reflectType.Elem().Field(i).Name,
+					o,
+				)
+			}
+			continue
+		}
 		if found == nil {
 			return fmt.Errorf(
 				"found no assignable value for field %s in type %s",
@@ -485,6 +679,139 @@ func (g *Graph) populateUnnamedInterface(o *Dew) error {
 	return nil
 }
 
+// assignable returns the Dews an unnamed inject may assign to a field of type
+// t: the unnamed ones or, if the option has a qualifier, every one carrying it.
+func (g *Graph) assignable(t reflect.Type, option Option) []*Dew {
+	var found []*Dew
+	for _, existing := range g.unnamed {
+		if existing.private || !existing.reflectType.AssignableTo(t) {
+			continue
+		}
+		if option.Qualifier == "" || existing.hasQualifier(option.Qualifier) {
+			found = append(found, existing)
+		}
+	}
+	if option.Qualifier == "" {
+		return found
+	}
+	var named []*Dew
+	for _, existing := range g.named {
+		if existing.reflectType.AssignableTo(t) && existing.hasQualifier(option.Qualifier) {
+			named = append(named, existing)
+		}
+	}
+	sort.Slice(named, func(i, j int) bool { return named[i].Name < named[j].Name })
+	return append(found, named...)
+}
+
+// preferPrimary returns the primary Dews if there are any, all of them
+// otherwise.
+func preferPrimary(dews []*Dew) []*Dew {
+	var primaries []*Dew
+	for _, o := range dews {
+		if o.Primary {
+			primaries = append(primaries, o)
+		}
+	}
+	if len(primaries) == 0 {
+		return dews
+	}
+	return primaries
+}
+
+// lookup returns the Dews assignable to type t, preferring the primary ones:
+// the unnamed Dews if there are any, the named ones otherwise.
+func (g *Graph) lookup(t reflect.Type) []*Dew {
+	if found := g.assignable(t, Option{}); len(found) != 0 {
+		return preferPrimary(found)
+	}
+	var found []*Dew
+	for _, existing := range g.named {
+		if existing.reflectType.AssignableTo(t) {
+			found = append(found, existing)
+		}
+	}
+	sortDews(found)
+	return preferPrimary(found)
+}
+
+// sortDews sorts dews by Order, then by name.
+func sortDews(dews []*Dew) {
+	sort.Slice(dews, func(i, j int) bool {
+		a, b := dews[i], dews[j]
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.reflectType.String() < b.reflectType.String()
+	})
+}
+
+// populateAll fills the auto slice or map field i of o. A slice collects the
+// unnamed Dews assignable to its elements, and the named ones too if the
+// option says so, ordered by Order then by name. A map collects the named Dews
+// keyed by name. A qualifier restricts both to the Dews carrying it, named
+// ones included.
+func (g *Graph) populateAll(o *Dew, i int, option Option) error {
+	field := o.reflectValue.Elem().Field(i)
+	fieldType := field.Type()
+	fieldName := o.reflectType.Elem().Field(i).Name
+
+	var found []*Dew
+	if fieldType.Kind() == reflect.Slice {
+		for _, existing := range g.unnamed {
+			if !existing.private && existing != o && existing.reflectType.AssignableTo(fieldType.Elem()) &&
+				(option.Qualifier == "" || existing.hasQualifier(option.Qualifier)) {
+				found = append(found, existing)
+			}
+		}
+	} else if fieldType.Key().Kind() != reflect.String {
+		return fmt.Errorf(
+			"expected string keys for auto map field %s in type %s",
+			fieldName,
+			o.reflectType,
+		)
+	}
+	if fieldType.Kind() == reflect.Map || option.Named || option.Qualifier != "" {
+		for _, existing := range g.named {
+			if existing != o && existing.reflectType.AssignableTo(fieldType.Elem()) &&
+				(option.Qualifier == "" || existing.hasQualifier(option.Qualifier)) {
+				found = append(found, existing)
+			}
+		}
+	}
+	sortDews(found)
+
+	if fieldType.Kind() == reflect.Slice {
+		newSlice := reflect.MakeSlice(fieldType, len(found), len(found))
+		for vi, existing := range found {
+			newSlice.Index(vi).Set(reflect.ValueOf(existing.Value))
+		}
+		field.Set(newSlice)
+	} else {
+		newMap := reflect.MakeMap(fieldType)
+		for _, existing := range found {
+			key := reflect.ValueOf(existing.Name).Convert(fieldType.Key())
+			newMap.SetMapIndex(key, reflect.ValueOf(existing.Value))
+		}
+		field.Set(newMap)
+	}
+	for _, existing := range found {
+		if g.Logger != nil {
+			g.Logger.Debugf(
+				"assigned existing %s to field %s in %s",
+				existing,
+				fieldName,
+				o,
+			)
+		}
+		o.addDep(fieldName, existing)
+	}
+	return nil
+}
+
 // Objects returns all known objects, named as well as unnamed. The returned
 // elements are not in a stable order.
 func (g *Graph) Objects() []*Dew {
@@ -1,7 +1,11 @@
 package summer
 
 import (
+	"fmt"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -647,3 +651,494 @@ func TestInjectSliceWithDew(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestInjectPrivate(t *testing.T) {
+	var g Graph
+	var v struct {
+		A *TypeAnswerStruct
+		B *TypeAnswerStruct
+	}
+	a := TypeAnswerStruct{}
+	if err := g.Provide(&Dew{Value: &a}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Provide(&Dew{
+		Value: &v,
+		Options: map[string]Option{
+			"A": Option{},
+			"B": Option{Private: true},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != &a {
+		t.Fatal("v.A isn't the provided instance")
+	}
+	if v.B == nil || v.B == &a {
+		t.Fatal("v.B isn't a new instance")
+	}
+}
+
+type TypePrivateNested struct {
+	A *TypeAnswerStruct `vapor:"auto"`
+	B *TypeAnswerStruct `vapor:"private"`
+}
+
+func TestInjectPrivateIsPopulated(t *testing.T) {
+	var g Graph
+	var v struct {
+		A *TypePrivateNested
+		B *TypePrivateNested
+	}
+	if err := g.Provide(&Dew{
+		Value: &v,
+		Options: map[string]Option{
+			"A": Option{Private: true},
+			"B": Option{Private: true},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.A == v.B {
+		t.Fatal("private instances are shared")
+	}
+	if v.A.A == nil || v.A.A != v.B.A {
+		t.Fatal("auto fields of private instances aren't shared")
+	}
+	if v.A.B == nil || v.A.B == v.B.B || v.A.B == v.A.A {
+		t.Fatal("private fields of private instances are shared")
+	}
+}
+
+func TestInjectPrivateInterface(t *testing.T) {
+	var g Graph
+	var v struct {
+		A Answerable
+	}
+	if err := g.Provide(&Dew{
+		Value:   &v,
+		Options: map[string]Option{"A": Option{Private: true}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := g.Populate()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	const msg = "found private inject option on interface field A in type *struct { A summer.Answerable }"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+type TypeTaggedLeaf struct {
+	A *TypeAnswerStruct `vapor:"auto"`
+}
+
+type TypeTaggedRoot struct {
+	Leaf    *TypeTaggedLeaf   `vapor:"auto"`
+	Private *TypeTaggedLeaf   `vapor:"private"`
+	Named   *TypeAnswerStruct `vapor:"name=answer"`
+	Ignored *TypeAnswerStruct
+}
+
+func TestProvideUsesTags(t *testing.T) {
+	var g Graph
+	var root TypeTaggedRoot
+	answer := TypeAnswerStruct{answer: 42}
+	if err := g.Provide(&Dew{Value: &root}, &Dew{Value: &answer, Name: "answer"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if root.Named != &answer || root.Ignored != nil {
+		t.Fatalf("unexpected %+v", root)
+	}
+	if root.Leaf == nil || root.Private == nil || root.Leaf == root.Private {
+		t.Fatalf("unexpected %+v", root)
+	}
+	if root.Leaf.A == nil || root.Leaf.A != root.Private.A {
+		t.Fatal("created dews weren't populated from their tags")
+	}
+}
+
+func TestProvideOptionsOverrideTags(t *testing.T) {
+	var g Graph
+	var root TypeTaggedRoot
+	answer := TypeAnswerStruct{answer: 42}
+	other := TypeAnswerStruct{answer: 7}
+	if err := g.Provide(
+		&Dew{Value: &root, Options: map[string]Option{"Named": Option{Name: "other"}}},
+		&Dew{Value: &answer, Name: "answer"},
+		&Dew{Value: &other, Name: "other"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if root.Named != &other {
+		t.Fatal("the provided option didn't override the tag")
+	}
+}
+
+func TestProvideInvalidTag(t *testing.T) {
+	var g Graph
+	var v struct {
+		A *TypeAnswerStruct `vapor:"auto,private"`
+	}
+	err := g.Provide(&Dew{Value: &v})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "invalid vapor tag on field A") {
+		t.Fatalf("unexpected error %s", err)
+	}
+}
+
+type testLogger struct {
+	debug []string
+}
+
+func (l *testLogger) Debugf(f string, args ...interface{}) {
+	l.debug = append(l.debug, fmt.Sprintf(f, args...))
+}
+
+func (l *testLogger) Errorf(f string, args ...interface{}) {}
+
+func TestInjectOptional(t *testing.T) {
+	logger := &testLogger{}
+	g := Graph{Logger: logger}
+	var v struct {
+		A Answerable        `vapor:"optional"`
+		B *TypeAnswerStruct `vapor:"name=missing,optional"`
+		C *TypeAnswerStruct `vapor:"optional"`
+		D *TypeNestedStruct `vapor:"optional"`
+	}
+	nested := TypeNestedStruct{}
+	if err := g.Provide(&Dew{Value: &v}, &Dew{Value: &nested, Complete: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.B != nil || v.C != nil {
+		t.Fatal("optional fields were set")
+	}
+	if v.A != &nested || v.D != &nested {
+		t.Fatal("optional fields weren't set to the existing dew")
+	}
+	skipped := 0
+	for _, line := range logger.debug {
+		if strings.HasPrefix(line, "skipped optional field") {
+			skipped++
+		}
+	}
+	if skipped != 2 {
+		t.Fatalf("expected 2 skipped fields in %q", logger.debug)
+	}
+}
+
+func TestInjectOptionalCreatedLater(t *testing.T) {
+	var g Graph
+	var optional struct {
+		A *TypeAnswerStruct `vapor:"optional"`
+	}
+	var auto struct {
+		A *TypeAnswerStruct `vapor:"auto"`
+	}
+	if err := g.Provide(&Dew{Value: &optional}, &Dew{Value: &auto}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if optional.A == nil || optional.A != auto.A {
+		t.Fatal("the optional field wasn't set to the created dew")
+	}
+}
+
+func TestInjectOptionalInterfaceMissing(t *testing.T) {
+	var g Graph
+	var v struct {
+		A Answerable `vapor:"optional"`
+	}
+	if err := g.Provide(&Dew{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != nil {
+		t.Fatal("v.A is not nil")
+	}
+}
+
+func TestInjectOptionalNamedMissing(t *testing.T) {
+	var g Graph
+	var v struct {
+		A Answerable            `vapor:"name=missing,optional"`
+		B []Answerable          `vapor:"name=missing,optional"`
+		C map[string]Answerable `vapor:"name=missing,optional"`
+	}
+	if err := g.Provide(&Dew{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != nil || v.B != nil || v.C != nil {
+		t.Fatal("optional fields were set")
+	}
+}
+
+func TestProvideTagValues(t *testing.T) {
+	var g Graph
+	var leaf, set TaggedLeaf
+	set.Port = 9090
+	if err := g.Provide(&Dew{Value: &leaf}, &Dew{Value: &set, Name: "set"}); err != nil {
+		t.Fatal(err)
+	}
+	if leaf.Port != 8080 {
+		t.Fatalf("expected 8080 but got %d", leaf.Port)
+	}
+	if set.Port != 9090 {
+		t.Fatal("overwrote a set field")
+	}
+}
+
+func TestProvideTagValuesSources(t *testing.T) {
+	g := Graph{
+		PropertySources: []PropertySource{MapSource{"level": "error"}},
+		Converters: map[reflect.Type]Converter{
+			reflect.TypeOf(test_level(0)): func(value string) (interface{}, error) {
+				if value != "error" {
+					return nil, fmt.Errorf("invalid level %s", value)
+				}
+				return test_level(2), nil
+			},
+		},
+	}
+	var v struct {
+		Level test_level `vapor:"value=${level}"`
+	}
+	if err := g.Provide(&Dew{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+	if v.Level != 2 {
+		t.Fatalf("expected 2 but got %d", v.Level)
+	}
+}
+
+func TestProvideTagValuesNoDotEnv(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("SUMMER_TEST_DOTENV=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	var g Graph
+	var v struct {
+		A int `vapor:"env=SUMMER_TEST_DOTENV,value=0"`
+	}
+	if err := g.Provide(&Dew{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 0 {
+		t.Fatal("read the .env file")
+	}
+}
+
+func TestProvideTagValuesError(t *testing.T) {
+	var g Graph
+	var v struct {
+		Port int `vapor:"value=http"`
+	}
+	err := g.Provide(&Dew{Value: &v})
+	if err == nil || !strings.Contains(err.Error(), "field Port") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+type TypeOtherAnswer struct{}
+
+func (t *TypeOtherAnswer) Answer() int {
+	return 7
+}
+
+func TestInjectAllSlice(t *testing.T) {
+	var g Graph
+	var v struct {
+		All      []Answerable          `vapor:"auto"`
+		Unnamed  []Answerable          `vapor:"auto,named"`
+		Map      map[string]Answerable `vapor:"auto"`
+		Explicit []Answerable          `vapor:"auto"`
+	}
+	explicit := []Answerable{&TypeOtherAnswer{}}
+	v.Explicit = explicit
+	a := TypeAnswerStruct{}
+	b := TypeAnswerStruct{}
+	c := TypeOtherAnswer{}
+	first := TypeOtherAnswer{}
+	if err := g.Provide(
+		&Dew{Value: &v},
+		&Dew{Value: &a, Name: "a"},
+		&Dew{Value: &b, Name: "b"},
+		&Dew{Value: &c},
+		&Dew{Value: &first, Order: -1, Name: "first"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.All) != 1 || v.All[0] != &c {
+		t.Fatalf("unexpected %v", v.All)
+	}
+	if len(v.Unnamed) != 4 || v.Unnamed[0] != &first || v.Unnamed[1] != &c || v.Unnamed[2] != &a || v.Unnamed[3] != &b {
+		t.Fatalf("unexpected %v", v.Unnamed)
+	}
+	if len(v.Map) != 3 || v.Map["a"] != &a || v.Map["b"] != &b || v.Map["first"] != &first {
+		t.Fatalf("unexpected %v", v.Map)
+	}
+	if &v.Explicit[0] != &explicit[0] {
+		t.Fatal("overwrote an existing slice")
+	}
+}
+
+func TestInjectAllMapKey(t *testing.T) {
+	var g Graph
+	var v struct {
+		A map[int]Answerable `vapor:"auto"`
+	}
+	if err := g.Provide(&Dew{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Populate()
+	if err == nil || !strings.Contains(err.Error(), "expected string keys") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestInjectPrimary(t *testing.T) {
+	var g Graph
+	var v struct {
+		A Answerable `vapor:"auto"`
+	}
+	a := TypeAnswerStruct{}
+	b := TypeOtherAnswer{}
+	if err := g.Provide(&Dew{Value: &v}, &Dew{Value: &a}, &Dew{Value: &b, Primary: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != &b {
+		t.Fatal("the primary dew didn't win")
+	}
+}
+
+func TestInjectTwoPrimaries(t *testing.T) {
+	var g Graph
+	var v struct {
+		A Answerable `vapor:"auto"`
+	}
+	a := TypeAnswerStruct{}
+	b := TypeOtherAnswer{}
+	c := TypeNestedStruct{}
+	if err := g.Provide(
+		&Dew{Value: &v},
+		&Dew{Value: &a, Primary: true},
+		&Dew{Value: &b, Primary: true},
+		&Dew{Value: &c, Complete: true},
+	); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Populate()
+	const msg = "found two assignable values for field A in type *struct { A summer.Answerable \"vapor:\\\"auto\\\"\" }. one type *summer.TypeAnswerStruct"
+	if err == nil || !strings.HasPrefix(err.Error(), msg) {
+		t.Fatalf("expected prefix:\n%s\nactual:\n%v", msg, err)
+	}
+}
+
+func TestInjectQualifier(t *testing.T) {
+	var g Graph
+	var v struct {
+		Read    Answerable        `vapor:"qualifier=read"`
+		Write   *TypeAnswerStruct `vapor:"qualifier=write"`
+		Readers []Answerable      `vapor:"auto,qualifier=read"`
+	}
+	read := TypeOtherAnswer{}
+	write := TypeAnswerStruct{}
+	replica := TypeNestedStruct{}
+	if err := g.Provide(
+		&Dew{Value: &v},
+		&Dew{Value: &read, Qualifiers: []string{"read"}, Primary: true},
+		&Dew{Value: &write, Name: "write", Qualifiers: []string{"write"}},
+		&Dew{Value: &replica, Name: "replica", Qualifiers: []string{"read"}, Complete: true},
+		&Dew{Value: &TypeAnswerStruct{}},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Read != &read || v.Write != &write {
+		t.Fatalf("unexpected %+v", v)
+	}
+	if len(v.Readers) != 2 || v.Readers[0] != &read || v.Readers[1] != &replica {
+		t.Fatalf("unexpected %v", v.Readers)
+	}
+}
+
+func TestInjectQualifierAmbiguous(t *testing.T) {
+	var g Graph
+	var v struct {
+		A *TypeAnswerStruct `vapor:"qualifier=read"`
+	}
+	err := g.Provide(
+		&Dew{Value: &v},
+		&Dew{Value: &TypeAnswerStruct{}, Name: "a", Qualifiers: []string{"read"}},
+		&Dew{Value: &TypeAnswerStruct{}, Name: "b", Qualifiers: []string{"read"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = g.Populate()
+	const msg = "found two assignable values for field A in type"
+	if err == nil || !strings.HasPrefix(err.Error(), msg) {
+		t.Fatalf("expected prefix:\n%s\nactual:\n%v", msg, err)
+	}
+}
+
+func TestInjectQualifierMissing(t *testing.T) {
+	var g Graph
+	var v struct {
+		A *TypeAnswerStruct `vapor:"qualifier=read"`
+	}
+	if err := g.Provide(&Dew{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Populate()
+	const msg = "found no assignable value qualified read for field A in type"
+	if err == nil || !strings.HasPrefix(err.Error(), msg) {
+		t.Fatalf("expected prefix:\n%s\nactual:\n%v", msg, err)
+	}
+}
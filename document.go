@@ -0,0 +1,370 @@
+package summer
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The document types are the shape shared by the XML, YAML and JSON formats.
+// They're only a decoding target and are converted into a Rain right away.
+
+// scalar is a const value. JSON numbers and booleans are taken as their text,
+// as XML attributes and YAML scalars are, so "value": 666 reads like "666".
+type scalar string
+
+func (s *scalar) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case nil:
+		*s = ""
+	case string:
+		*s = scalar(value)
+	case float64, bool:
+		*s = scalar(data)
+	default:
+		return fmt.Errorf("expected a string, number or boolean value but got %s", data)
+	}
+	return nil
+}
+
+type subVaporDocument struct {
+	Name  string             `xml:"name,attr" yaml:"name" json:"name"`
+	Dew   string             `xml:"dew,attr" yaml:"dew" json:"dew"`
+	Value scalar             `xml:"value,attr" yaml:"value" json:"value"`
+	List  []subVaporDocument `xml:"vapor" yaml:"vapor" json:"vapor"`
+	pos   Position
+}
+
+func (v *subVaporDocument) locate(at func(string) Position, path string) {
+	v.pos = at(path)
+	for i := range v.List {
+		v.List[i].locate(at, elementPath(path, "vapor", i))
+	}
+}
+
+func (v *subVaporDocument) definition() VaporDefinition {
+	vapor := VaporDefinition{
+		Name:     v.Name,
+		Dew:      v.Dew,
+		Value:    string(v.Value),
+		Position: v.pos,
+	}
+	for i := range v.List {
+		vapor.List = append(vapor.List, v.List[i].definition())
+	}
+	return vapor
+}
+
+type vaporDocument struct {
+	Name      string             `xml:"name,attr" yaml:"name" json:"name"`
+	Dew       string             `xml:"dew,attr" yaml:"dew" json:"dew"`
+	Value     scalar             `xml:"value,attr" yaml:"value" json:"value"`
+	Private   bool               `xml:"private,attr" yaml:"private" json:"private"`
+	Auto      bool               `xml:"auto,attr" yaml:"auto" json:"auto"`
+	Optional  bool               `xml:"optional,attr" yaml:"optional" json:"optional"`
+	Named     bool               `xml:"named,attr" yaml:"named" json:"named"`
+	Qualifier string             `xml:"qualifier,attr" yaml:"qualifier" json:"qualifier"`
+	List      []subVaporDocument `xml:"vapor" yaml:"vapor" json:"vapor"`
+	pos       Position
+}
+
+type dewDocument struct {
+	Id        string          `xml:"id,attr" yaml:"id" json:"id"`
+	Class     string          `xml:"class,attr" yaml:"class" json:"class"`
+	Impl      string          `xml:"impl,attr" yaml:"impl" json:"impl"`
+	Profile   string          `xml:"profile,attr" yaml:"profile" json:"profile"`
+	Order     int             `xml:"order,attr" yaml:"order" json:"order"`
+	Primary   bool            `xml:"primary,attr" yaml:"primary" json:"primary"`
+	Qualifier string          `xml:"qualifier,attr" yaml:"qualifier" json:"qualifier"`
+	Vapor     []vaporDocument `xml:"vapor" yaml:"vapor" json:"vapor"`
+	pos       Position
+}
+
+type importDocument struct {
+	Resource string `xml:"resource,attr" yaml:"resource" json:"resource"`
+	pos      Position
+}
+
+type rainDocument struct {
+	XMLName xml.Name         `xml:"rain" yaml:"-" json:"-"`
+	Profile string           `xml:"profile,attr" yaml:"profile" json:"profile"`
+	Import  []importDocument `xml:"import" yaml:"import" json:"import"`
+	Dew     []dewDocument    `xml:"dew" yaml:"dew" json:"dew"`
+	Rain    []rainDocument   `xml:"rain" yaml:"rain" json:"rain"`
+}
+
+// locate sets the positions of the block's elements from an index of
+// element paths, see positionIndex.
+func (r *rainDocument) locate(index positionIndex, path, filename string) {
+	at := func(path string) Position {
+		pos := index[path]
+		pos.File = filename
+		return pos
+	}
+	for i := range r.Import {
+		r.Import[i].pos = at(elementPath(path, "import", i))
+	}
+	for i := range r.Dew {
+		d := &r.Dew[i]
+		dewPath := elementPath(path, "dew", i)
+		d.pos = at(dewPath)
+		for j := range d.Vapor {
+			v := &d.Vapor[j]
+			vaporPath := elementPath(dewPath, "vapor", j)
+			v.pos = at(vaporPath)
+			for k := range v.List {
+				v.List[k].locate(at, elementPath(vaporPath, "vapor", k))
+			}
+		}
+	}
+	for i := range r.Rain {
+		r.Rain[i].locate(index, elementPath(path, "rain", i), filename)
+	}
+}
+
+// definition converts a dew, which is enclosed in rain blocks restricted to
+// the given profiles.
+func (d *dewDocument) definition(profiles []string) DewDefinition {
+	dew := DewDefinition{
+		Id:       d.Id,
+		Class:    d.Class,
+		Impl:     d.Impl,
+		Profiles: withProfile(profiles, d.Profile),
+		Order:    d.Order,
+		Primary:  d.Primary,
+		Position: d.pos,
+		Vapors:   make([]VaporDefinition, len(d.Vapor)),
+	}
+	if d.Qualifier != "" {
+		for _, q := range strings.Split(d.Qualifier, ",") {
+			dew.Qualifiers = append(dew.Qualifiers, strings.TrimSpace(q))
+		}
+	}
+	for j, v := range d.Vapor {
+		vapor := VaporDefinition{
+			Name:      v.Name,
+			Dew:       v.Dew,
+			Value:     string(v.Value),
+			Private:   v.Private,
+			Auto:      v.Auto,
+			Optional:  v.Optional,
+			Named:     v.Named,
+			Qualifier: v.Qualifier,
+			Position:  v.pos,
+		}
+		for k := range v.List {
+			vapor.List = append(vapor.List, v.List[k].definition())
+		}
+		dew.Vapors[j] = vapor
+	}
+	return dew
+}
+
+func withProfile(profiles []string, profile string) []string {
+	if profile == "" {
+		return profiles
+	}
+	return append(profiles[:len(profiles):len(profiles)], profile)
+}
+
+// format decodes one configuration format.
+type format struct {
+	unmarshal func(data []byte, v interface{}) error
+	positions func(data []byte) positionIndex
+}
+
+var (
+	xmlFormat  = &format{unmarshal: xml.Unmarshal, positions: xmlPositions}
+	yamlFormat = &format{unmarshal: yaml.Unmarshal, positions: yamlPositions}
+	jsonFormat = &format{unmarshal: json.Unmarshal, positions: jsonPositions}
+)
+
+// documentLoader decodes a configuration together with everything it imports.
+// Imported resources are resolved relative to the importing file and decoded
+// according to their extension, falling back to the format of the importing
+// document.
+type documentLoader struct {
+	formats map[string]*format
+	loading []string        // Files being loaded, to detect cycles
+	loaded  map[string]bool // Files loaded by profiles, see loadedKey
+	rain    Rain
+}
+
+func newDocumentLoader() *documentLoader {
+	return &documentLoader{
+		formats: map[string]*format{
+			".xml":  xmlFormat,
+			".yaml": yamlFormat,
+			".yml":  yamlFormat,
+			".json": jsonFormat,
+		},
+		loaded: make(map[string]bool),
+	}
+}
+
+// loadDocument decodes data, which was read from filename if it isn't empty,
+// and the resources it imports into a single Rain.
+func loadDocument(data []byte, filename string, f *format) (*Rain, error) {
+	l := newDocumentLoader()
+	if filename != "" {
+		abs, err := filepath.Abs(filename)
+		if err != nil {
+			return nil, err
+		}
+		filename = abs
+		l.loaded[loadedKey(filename, nil)] = true
+	}
+	if err := l.load(data, filename, f); err != nil {
+		return nil, err
+	}
+	return &l.rain, nil
+}
+
+// readDocument is loadDocument reading filename itself when data is nil.
+func readDocument(data []byte, filename string, f *format) (*Rain, error) {
+	if data == nil && filename != "" {
+		var err error
+		if data, err = ioutil.ReadFile(filename); err != nil {
+			return nil, err
+		}
+	}
+	return loadDocument(data, filename, f)
+}
+
+// loadFormat builds a Graph from a configuration in format f, see
+// readDocument. Every format goes through it so they can't drift apart.
+func (c *Container) loadFormat(data []byte, filename string, f *format, logger Logger, opts []LoadOption) (*Graph, error) {
+	def, err := readDocument(data, filename, f)
+	if err != nil {
+		return nil, err
+	}
+	return c.Build(def, logger, opts...)
+}
+
+// validateFormat checks a configuration in format f, see loadFormat.
+func (c *Container) validateFormat(data []byte, filename string, f *format, opts []LoadOption) error {
+	def, err := readDocument(data, filename, f)
+	if err != nil {
+		return err
+	}
+	return c.Validate(def, opts...)
+}
+
+func (l *documentLoader) load(data []byte, filename string, f *format) error {
+	var r rainDocument
+	if err := f.unmarshal(data, &r); err != nil {
+		return &ConfigError{Position: errorPosition(err, data, filename), Err: err}
+	}
+	r.locate(f.positions(data), "", filename)
+	l.loading = append(l.loading, filename)
+	defer func() { l.loading = l.loading[:len(l.loading)-1] }()
+	return l.collect(&r, filename, f, nil)
+}
+
+// collect adds the dews of a rain block, the resources it imports and its
+// nested blocks. Everything inherits the profiles of the enclosing blocks.
+func (l *documentLoader) collect(r *rainDocument, filename string, f *format, profiles []string) error {
+	profiles = withProfile(profiles, r.Profile)
+	dir := "."
+	if filename != "" {
+		dir = filepath.Dir(filename)
+	}
+	for _, imp := range r.Import {
+		files, err := resolveImport(dir, imp.Resource)
+		if err != nil {
+			return &ConfigError{Position: imp.pos, Err: fmt.Errorf("import %s: %w", imp.Resource, err)}
+		}
+		for _, file := range files {
+			start := len(l.rain.Dews)
+			if err := l.loadFile(file, f, profiles); err != nil {
+				if _, ok := err.(*ConfigError); !ok {
+					err = &ConfigError{Position: imp.pos, Err: fmt.Errorf("import %s: %w", imp.Resource, err)}
+				}
+				return err
+			}
+			for i := start; i < len(l.rain.Dews); i++ {
+				l.rain.Dews[i].Profiles = append(profiles[:len(profiles):len(profiles)], l.rain.Dews[i].Profiles...)
+			}
+		}
+	}
+	for i := range r.Dew {
+		l.rain.Dews = append(l.rain.Dews, r.Dew[i].definition(profiles))
+	}
+	for i := range r.Rain {
+		if err := l.collect(&r.Rain[i], filename, f, profiles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadedKey identifies filename imported under profiles. A file imported
+// again under other profiles is loaded again, its dews being active under
+// either of them.
+func loadedKey(filename string, profiles []string) string {
+	return filename + "\x00" + strings.Join(profiles, ",")
+}
+
+func (l *documentLoader) loadFile(filename string, fallback *format, profiles []string) error {
+	for i, loading := range l.loading {
+		if loading == filename {
+			cycle := append(append([]string{}, l.loading[i:]...), filename)
+			return fmt.Errorf("import cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	key := loadedKey(filename, profiles)
+	if l.loaded[key] {
+		return nil
+	}
+	l.loaded[key] = true
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	f := l.formats[strings.ToLower(filepath.Ext(filename))]
+	if f == nil {
+		f = fallback
+	}
+	return l.load(data, filename, f)
+}
+
+// resolveImport returns the absolute paths of the files an import refers to.
+// Glob patterns may match nothing, plain paths must exist.
+func resolveImport(dir, resource string) ([]string, error) {
+	if resource == "" {
+		return nil, fmt.Errorf("expected a resource")
+	}
+	if !filepath.IsAbs(resource) {
+		resource = filepath.Join(dir, resource)
+	}
+	resource, err := filepath.Abs(resource)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.ContainsAny(resource, "*?[") {
+		return []string{resource}, nil
+	}
+	files, err := filepath.Glob(resource)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func displayFile(filename string) string {
+	if filename == "" {
+		return "<input>"
+	}
+	return filename
+}
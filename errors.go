@@ -0,0 +1,90 @@
+package summer
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ConfigError is an error in a configuration. It locates the dew or vapor at
+// fault and wraps the underlying cause, such as a *strconv.NumError.
+type ConfigError struct {
+	Position
+	Dew   string // Class#Id of the dew at fault, if any
+	Vapor string // Name of the vapor at fault, if any
+	Err   error
+}
+
+func (e *ConfigError) Error() string {
+	var buf bytes.Buffer
+	if pos := e.Position.String(); pos != "" {
+		buf.WriteString(pos)
+		buf.WriteString(": ")
+	}
+	if e.Dew != "" {
+		buf.WriteString("dew ")
+		buf.WriteString(e.Dew)
+		if e.Vapor != "" {
+			buf.WriteString(", vapor ")
+			buf.WriteString(e.Vapor)
+		}
+		buf.WriteString(": ")
+	}
+	buf.WriteString(e.Err.Error())
+	return buf.String()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+func dewError(d *DewDefinition, err error) error {
+	return &ConfigError{
+		Position: d.Position,
+		Dew:      d.Class + "#" + d.Id,
+		Err:      err,
+	}
+}
+
+func vaporError(d *DewDefinition, v *VaporDefinition, err error) error {
+	pos := v.Position
+	if pos.Line == 0 {
+		pos = d.Position
+	}
+	return &ConfigError{
+		Position: pos,
+		Dew:      d.Class + "#" + d.Id,
+		Vapor:    v.Name,
+		Err:      err,
+	}
+}
+
+// elementError locates an error at an element of a list, array or map vapor.
+func elementError(d *DewDefinition, v, element *VaporDefinition, err error) error {
+	e := vaporError(d, v, err).(*ConfigError)
+	if element.Line > 0 {
+		e.Position = element.Position
+	}
+	return e
+}
+
+// ConfigErrors reports every problem found in a configuration at once, see
+// WithValidation.
+type ConfigErrors []error
+
+func (e ConfigErrors) Error() string {
+	var buf bytes.Buffer
+	if len(e) == 1 {
+		buf.WriteString("found 1 configuration error:")
+	} else {
+		fmt.Fprintf(&buf, "found %d configuration errors:", len(e))
+	}
+	for _, err := range e {
+		buf.WriteString("\n\t")
+		buf.WriteString(err.Error())
+	}
+	return buf.String()
+}
+
+func (e ConfigErrors) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,139 @@
+package summer
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestConfigError_XML(t *testing.T) {
+	con := new(Container)
+	con.Register(StructAnswer{})
+	config := []byte(`<rain>
+<dew id="test" class="summer.StructAnswer">
+	<vapor name="Ans" value="abc" />
+</dew>
+</rain>
+`)
+	_, err := con.XMLConfigurationContainer(config, nil)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("expected a ConfigError, got %v", err)
+	}
+	if configErr.Line != 3 || configErr.Column != 2 || configErr.Vapor != "Ans" || configErr.Dew != "summer.StructAnswer#test" {
+		t.Fatalf("unexpected error %+v", configErr)
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Fatalf("expected the strconv error to be wrapped, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "<input>:3:2: dew summer.StructAnswer#test, vapor Ans: ") {
+		t.Fatalf("unexpected message %s", err)
+	}
+}
+
+func TestConfigError_Populate(t *testing.T) {
+	con := new(Container)
+	con.Register(HandlerA{})
+	con.Register(HandlerB{})
+	con.Register(AnswerSpeaker{})
+	con.Register(QualifiedUser{})
+	config := []byte(`<rain>
+<dew class="summer.HandlerA" />
+<dew class="summer.HandlerB" />
+<dew id="speaker" class="summer.AnswerSpeaker">
+	<vapor name="Answer" auto="true" />
+</dew>
+</rain>
+`)
+	_, err := con.XMLConfigurationContainer(config, nil)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("expected a ConfigError, got %v", err)
+	}
+	if configErr.Line != 5 || configErr.Vapor != "Answer" || configErr.Dew != "summer.AnswerSpeaker#speaker" {
+		t.Fatalf("unexpected error %+v", configErr)
+	}
+
+	config = []byte(`<rain>
+<dew class="summer.HandlerA" qualifier="read" />
+<dew class="summer.HandlerB" qualifier="read" />
+<dew id="user" class="summer.QualifiedUser" />
+</rain>
+`)
+	_, err = con.XMLConfigurationContainer(config, nil)
+	if !errors.As(err, &configErr) {
+		t.Fatalf("expected a ConfigError, got %v", err)
+	}
+	if configErr.Line != 4 || configErr.Vapor != "Reader" {
+		t.Fatalf("unexpected error %+v", configErr)
+	}
+}
+
+func TestConfigError_File(t *testing.T) {
+	con := new(Container)
+	con.Register(StructAnswer{})
+	_, err := con.XMLFileConfigurationContainer("testdata/errors/unknown.xml", nil)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("expected a ConfigError, got %v", err)
+	}
+	if !strings.HasSuffix(configErr.File, "unknown.xml") || configErr.Line != 5 || configErr.Column != 1 {
+		t.Fatalf("unexpected error %+v", configErr)
+	}
+}
+
+func TestConfigError_YAML(t *testing.T) {
+	con := new(Container)
+	con.Register(StructAnswer{})
+	con.Register(StructInlineTest{})
+	config := []byte(`
+dew:
+  - id: test
+    class: summer.StructInlineTest
+    vapor:
+      - name: List
+        vapor:
+          - value: ok
+          - value: ${missing}
+`)
+	_, err := con.YAMLConfigurationContainer(config, nil, WithPropertySources(MapSource{}))
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("expected a ConfigError, got %v", err)
+	}
+	if configErr.Line != 9 || configErr.Column != 13 || configErr.Vapor != "List" {
+		t.Fatalf("unexpected error %+v", configErr)
+	}
+}
+
+func TestConfigError_JSON(t *testing.T) {
+	con := new(Container)
+	con.Register(AnswerSpeaker{})
+	con.Register(StructAnswer{})
+	config := []byte(`{"dew": [
+	{"id": "test", "class": "summer.StructAnswer", "vapor": [{"name": "Ans", "value": "1"}]},
+	{"id": "checker", "class": "summer.AnswerSpeaker", "vapor": [{"name": "Answer", "dew": "missing"}]}
+]}`)
+	_, err := con.JSONConfigurationContainer(config, nil)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("expected a ConfigError, got %v", err)
+	}
+	if configErr.Line != 3 || configErr.Column != 63 || configErr.Vapor != "Answer" {
+		t.Fatalf("unexpected error %+v", configErr)
+	}
+}
+
+func TestConfigError_Syntax(t *testing.T) {
+	con := new(Container)
+	_, err := con.JSONConfigurationContainer([]byte("{\n\"dew\": [}"), nil)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("expected a ConfigError, got %v", err)
+	}
+	if configErr.Line != 2 {
+		t.Fatalf("unexpected error %+v", configErr)
+	}
+}
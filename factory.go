@@ -0,0 +1,248 @@
+package summer
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// factory is a constructor function provided to a Graph.
+type factory struct {
+	fn      reflect.Value
+	first   *Dew // Dew of the first result, its Value is set by the call
+	calling bool
+	called  bool
+}
+
+func (f *factory) String() string {
+	return runtime.FuncForPC(f.fn.Pointer()).Name()
+}
+
+// results returns the types of the values returned by the function, leaving
+// the trailing error out.
+func (f *factory) results() []reflect.Type {
+	t := f.fn.Type()
+	var results []reflect.Type
+	for i := 0; i < t.NumOut(); i++ {
+		if i < t.NumOut()-1 || t.Out(i) != errorType {
+			results = append(results, t.Out(i))
+		}
+	}
+	return results
+}
+
+// checkFactory checks that fn can be used as a factory: a function returning
+// at least one value, optionally followed by an error.
+func checkFactory(fn interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return reflect.Value{}, fmt.Errorf("expected a function but got %T", fn)
+	}
+	t := v.Type()
+	if t.IsVariadic() {
+		return reflect.Value{}, fmt.Errorf("variadic function %s can't be a factory", t)
+	}
+	n := t.NumOut()
+	if n > 0 && t.Out(n-1) == errorType {
+		n--
+	}
+	if n == 0 {
+		return reflect.Value{}, fmt.Errorf("function %s returns no value", t)
+	}
+	return v, nil
+}
+
+// ProvideFunc provides the values returned by fn to the Graph, as unnamed
+// Dews. fn may return an error after them, which aborts Populate. fn is called
+// once at the start of Populate, with each parameter set to the Dew assignable
+// to it, the values returned by other functions included. Unnamed Dews are
+// looked up first, then named ones, the primary ones winning ties. The
+// parameters, and the Dews they depend on, are populated before fn is called.
+// The Dews returned by fn depend on its parameters, so Start and Stop order
+// them accordingly.
+func (g *Graph) ProvideFunc(fn interface{}) error {
+	return g.provideFunc(fn, &Dew{})
+}
+
+// provideFunc provides fn, first holding the fields of the Dew of its first
+// result such as its Name.
+func (g *Graph) provideFunc(fn interface{}, first *Dew) error {
+	v, err := checkFactory(fn)
+	if err != nil {
+		return err
+	}
+	g.factories = append(g.factories, &factory{fn: v, first: first})
+	return nil
+}
+
+// callFactories calls every function provided to the Graph which hasn't been
+// called yet.
+func (g *Graph) callFactories() error {
+	for _, f := range g.factories {
+		if err := g.call(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Graph) call(f *factory) error {
+	if f.called {
+		return nil
+	}
+	if f.calling {
+		return fmt.Errorf("found a dependency cycle through function %s", f)
+	}
+	f.calling = true
+	defer func() { f.calling = false }()
+
+	t := f.fn.Type()
+	args := make([]reflect.Value, t.NumIn())
+	deps := make([]*Dew, t.NumIn())
+	for i := range args {
+		dep, err := g.resolveParam(f, i)
+		if err != nil {
+			return err
+		}
+		if err := g.populateEarly(dep); err != nil {
+			return err
+		}
+		args[i] = reflect.ValueOf(dep.Value)
+		deps[i] = dep
+	}
+	results := f.fn.Call(args)
+	f.called = true
+	if n := len(results); t.Out(n-1) == errorType {
+		if err, _ := results[n-1].Interface().(error); err != nil {
+			return fmt.Errorf("function %s: %w", f, err)
+		}
+		results = results[:n-1]
+	}
+
+	for j, result := range results {
+		if (result.Kind() == reflect.Ptr || result.Kind() == reflect.Interface) && result.IsNil() {
+			return fmt.Errorf("function %s returned a nil %s", f, result.Type())
+		}
+		o := &Dew{}
+		if j == 0 {
+			o = f.first
+		}
+		o.Value = result.Interface()
+		o.created = true
+		if err := g.Provide(o); err != nil {
+			return fmt.Errorf("function %s: %w", f, err)
+		}
+		for i, dep := range deps {
+			o.addDep(strconv.Itoa(i), dep)
+		}
+	}
+	return nil
+}
+
+// resolveParam returns the Dew for parameter i of f, calling the functions
+// whose results may be assignable to it first.
+func (g *Graph) resolveParam(f *factory, i int) (*Dew, error) {
+	t := f.fn.Type().In(i)
+	if err := g.callAssignable(t); err != nil {
+		return nil, err
+	}
+
+	candidates := g.lookup(t)
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("found no assignable value for parameter %d (%s) of function %s", i, t, f)
+	case 1:
+		return candidates[0], nil
+	}
+	return nil, fmt.Errorf(
+		"found two assignable values for parameter %d (%s) of function %s: %s and %s",
+		i,
+		t,
+		f,
+		candidates[0],
+		candidates[1],
+	)
+}
+
+// callAssignable calls the functions whose results may be assignable to t.
+func (g *Graph) callAssignable(t reflect.Type) error {
+	for _, other := range g.factories {
+		if other.called {
+			continue
+		}
+		for _, result := range other.results() {
+			if result.AssignableTo(t) {
+				if err := g.call(other); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// populateEarly populates o, and the Dews it depends on, ahead of Populate so
+// that a function gets them as it would once Populate is done. The functions
+// whose results may be injected into o are called first.
+func (g *Graph) populateEarly(o *Dew) error {
+	if o.Complete || o.populated {
+		return nil
+	}
+	o.populated = true
+	if isStructPtr(o.reflectType) {
+		for name := range o.Options {
+			field, ok := o.reflectType.Elem().FieldByName(name)
+			if !ok {
+				continue
+			}
+			t := field.Type
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+			if err := g.callAssignable(t); err != nil {
+				return err
+			}
+		}
+	}
+	if err := g.populateExplicit(o); err != nil {
+		return err
+	}
+	if err := g.populateUnnamedInterface(o); err != nil {
+		return err
+	}
+	for _, dep := range o.Dependencies {
+		if err := g.populateEarly(dep.Object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterFactory registers fn as the class name. A dew of that class is the
+// first value fn returns, see Graph.ProvideFunc. Its id names that value and
+// it doesn't take any vapor.
+func (c *Container) RegisterFactory(name string, fn interface{}) error {
+	v, err := checkFactory(fn)
+	if err != nil {
+		return err
+	}
+	return c.registerFactory(name, v)
+}
+
+func (c *Container) registerFactory(name string, v reflect.Value) error {
+	if existing := c.factories[name]; existing.IsValid() && existing.Pointer() == v.Pointer() {
+		return nil
+	}
+	if c.GetType(name) != nil || c.factories[name].IsValid() {
+		return c.conflict(fmt.Errorf("class %s is already registered", name))
+	}
+	if c.factories == nil {
+		c.factories = make(map[string]reflect.Value)
+	}
+	c.factories[name] = v
+	return nil
+}
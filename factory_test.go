@@ -0,0 +1,177 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type FactoryConfig struct {
+	URL string
+}
+
+type FactoryClient struct {
+	Config  *FactoryConfig
+	started bool
+}
+
+func (c *FactoryClient) Start(ctx context.Context) error {
+	if c.Config == nil {
+		return errors.New("started before its config")
+	}
+	c.started = true
+	return nil
+}
+
+func NewFactoryClient(cfg *FactoryConfig) (*FactoryClient, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("expected a URL")
+	}
+	return &FactoryClient{Config: cfg}, nil
+}
+
+type FactoryUser struct {
+	Client *FactoryClient `vapor:"auto"`
+}
+
+func TestProvideFunc(t *testing.T) {
+	var g Graph
+	var user FactoryUser
+	if err := g.Provide(&Dew{Value: &user}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.ProvideFunc(NewFactoryClient); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.ProvideFunc(func() *FactoryConfig { return &FactoryConfig{URL: "db://"} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if user.Client == nil || user.Client.Config.URL != "db://" {
+		t.Fatalf("unexpected %+v", user.Client)
+	}
+	if err := g.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !user.Client.started {
+		t.Fatal("the client wasn't started")
+	}
+	g.Stop(context.Background())
+}
+
+type FactoryDep struct{}
+
+type FactoryDepConfig struct {
+	Dep    *FactoryDep `vapor:"auto"`
+	Answer Answerable  `vapor:"auto"`
+}
+
+type FactoryDepClient struct {
+	Dep    *FactoryDep
+	Answer Answerable
+}
+
+func TestProvideFuncPopulatesParameters(t *testing.T) {
+	var g Graph
+	var cfg FactoryDepConfig
+	var client *FactoryDepClient
+	if err := g.ProvideFunc(func(c *FactoryDepConfig) *FactoryDepClient {
+		client = &FactoryDepClient{Dep: c.Dep, Answer: c.Answer}
+		return client
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Provide(&Dew{Value: &cfg}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.ProvideFunc(func() *TypeAnswerStruct { return &TypeAnswerStruct{} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if client.Dep == nil || client.Dep != cfg.Dep {
+		t.Fatalf("the function got an unpopulated parameter: %+v", client)
+	}
+	if client.Answer == nil || client.Answer != cfg.Answer {
+		t.Fatalf("the function got an unpopulated interface field: %+v", client)
+	}
+}
+
+func TestProvideFuncError(t *testing.T) {
+	var g Graph
+	if err := g.ProvideFunc(NewFactoryClient); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Provide(&Dew{Value: &FactoryConfig{}}); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Populate()
+	if err == nil || !strings.HasSuffix(err.Error(), "NewFactoryClient: expected a URL") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestProvideFuncMissingParameter(t *testing.T) {
+	var g Graph
+	if err := g.ProvideFunc(NewFactoryClient); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Populate()
+	if err == nil || !strings.HasPrefix(err.Error(), "found no assignable value for parameter 0 (*summer.FactoryConfig)") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestProvideFuncCycle(t *testing.T) {
+	var g Graph
+	g.ProvideFunc(func(*TypeAnswerStruct) *TypeNestedStruct { return &TypeNestedStruct{} })
+	g.ProvideFunc(func(*TypeNestedStruct) *TypeAnswerStruct { return &TypeAnswerStruct{} })
+	err := g.Populate()
+	if err == nil || !strings.HasPrefix(err.Error(), "found a dependency cycle") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestProvideFuncInvalid(t *testing.T) {
+	var g Graph
+	for _, fn := range []interface{}{nil, 42, func() {}, func() error { return nil }, func(...int) int { return 0 }} {
+		if err := g.ProvideFunc(fn); err == nil {
+			t.Fatalf("expected an error for %T", fn)
+		}
+	}
+}
+
+func TestContainer_RegisterFactory(t *testing.T) {
+	con := new(Container)
+	con.Register(FactoryConfig{})
+	con.Register(FactoryUser{})
+	if err := con.RegisterFactory("client", NewFactoryClient); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.RegisterFactory("summer.FactoryConfig", NewFactoryClient); err == nil {
+		t.Fatal("expected an error")
+	}
+	config := []byte(`
+<rain>
+<dew class="summer.FactoryConfig">
+	<vapor name="URL" value="db://" />
+</dew>
+<dew id="client" class="client" />
+<dew id="user" class="summer.FactoryUser">
+	<vapor name="Client" dew="client" />
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := app.GetDewByName("client").Value.(*FactoryClient)
+	if client.Config.URL != "db://" || app.GetDewByName("user").Value.(*FactoryUser).Client != client {
+		t.Fatalf("unexpected %+v", client)
+	}
+}
@@ -0,0 +1,21 @@
+package summer
+
+// JSONConfigurationContainer builds a Graph from a JSON configuration. The
+// document follows rain.schema.json, which mirrors the XML format: a top
+// level "dew" array, each dew carrying an id, a class and a "vapor" array.
+func (c *Container) JSONConfigurationContainer(data []byte, logger Logger, opts ...LoadOption) (*Graph, error) {
+	return c.loadFormat(data, "", jsonFormat, logger, opts)
+}
+
+func (c *Container) JSONFileConfigurationContainer(filename string, logger Logger, opts ...LoadOption) (*Graph, error) {
+	return c.loadFormat(nil, filename, jsonFormat, logger, opts)
+}
+
+// ValidateJSON checks a JSON configuration without building it, see Validate.
+func (c *Container) ValidateJSON(data []byte, opts ...LoadOption) error {
+	return c.validateFormat(data, "", jsonFormat, opts)
+}
+
+func (c *Container) ValidateJSONFile(filename string, opts ...LoadOption) error {
+	return c.validateFormat(nil, filename, jsonFormat, opts)
+}
@@ -0,0 +1,139 @@
+package summer
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContainer_JSONConfigurationContainer(t *testing.T) {
+	con := new(Container)
+	con.Register(AnswerSpeaker{})
+	con.Register(StructAnswer{})
+	config := []byte(`{
+	"dew": [
+		{"id": "test", "class": "summer.StructAnswer", "vapor": [{"name": "Ans", "value": "666"}]},
+		{"id": "checker", "class": "summer.AnswerSpeaker", "vapor": [{"name": "Answer", "dew": "test"}]}
+	]
+}`)
+	app, err := con.JSONConfigurationContainer(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Start(context.Background()) != nil {
+		t.Fail()
+	}
+	app.Stop(context.Background())
+}
+
+func TestContainer_JSONEnvOverride(t *testing.T) {
+	con := new(Container)
+	con.Register(StructInlineTest{})
+	con.Register(StructAnswer{})
+	config := []byte(`{
+	"dew": [
+		{"id": "answer", "class": "summer.StructAnswer", "vapor": [{"name": "Ans", "value": "1"}]},
+		{"id": "test", "class": "summer.StructInlineTest", "vapor": [
+			{"name": "List", "vapor": [{"value": "test1"}, {"value": "test2"}]},
+			{"name": "Map", "vapor": [{"name": "key1", "value": "test1"}]}
+		]}
+	]
+}`)
+	os.Setenv("summer.StructAnswer.Ans", "666")
+	defer os.Unsetenv("summer.StructAnswer.Ans")
+	app, err := con.JSONConfigurationContainer(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.GetDewByName("answer").Value.(*StructAnswer).Ans != 666 {
+		t.Fail()
+	}
+	test := app.GetDewByName("test").Value.(*StructInlineTest)
+	if len(test.List) != 2 || test.Map["key1"] != "test1" {
+		t.Fail()
+	}
+}
+
+func TestContainer_JSONScalars(t *testing.T) {
+	con := new(Container)
+	con.Register(test_struct{})
+	con.Register(StructInlineTest{})
+	config := []byte(`{
+	"dew": [
+		{"id": "scalars", "class": "summer.test_struct", "vapor": [
+			{"name": "Int", "value": -666},
+			{"name": "Bool", "value": true},
+			{"name": "Str", "value": null}
+		]},
+		{"id": "list", "class": "summer.StructInlineTest", "vapor": [
+			{"name": "List", "vapor": [{"value": 1.5}, {"value": "two"}, {"value": false}]}
+		]}
+	]
+}`)
+	app, err := con.JSONConfigurationContainer(config, nil, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := app.GetDewByName("scalars").Value.(*test_struct)
+	if s.Int != -666 || !s.Bool || s.Str != "" {
+		t.Fatalf("unexpected %+v", s)
+	}
+	if list := app.GetDewByName("list").Value.(*StructInlineTest); strings.Join(list.List, " ") != "1.5 two false" {
+		t.Fatalf("unexpected %+v", list)
+	}
+
+	config = []byte(`{"dew": [{"class": "summer.test_struct", "vapor": [{"name": "Int", "value": [1]}]}]}`)
+	if _, err := con.JSONConfigurationContainer(config, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestContainer_JSONBadJSON(t *testing.T) {
+	con := new(Container)
+	app, _ := con.JSONConfigurationContainer([]byte(`{"dew": [`), nil)
+	if app != nil {
+		t.Fail()
+	}
+}
+
+// The schema must describe every attribute the decoder understands.
+func TestJSONSchema(t *testing.T) {
+	data, err := ioutil.ReadFile("rain.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Properties  map[string]interface{}
+		Definitions map[string]struct {
+			Properties map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		Properties map[string]interface{}
+		Type       reflect.Type
+	}{
+		{schema.Properties, reflect.TypeOf(rainDocument{})},
+		{schema.Definitions["import"].Properties, reflect.TypeOf(importDocument{})},
+		{schema.Definitions["dew"].Properties, reflect.TypeOf(dewDocument{})},
+		{schema.Definitions["vapor"].Properties, reflect.TypeOf(vaporDocument{})},
+		{schema.Definitions["subVapor"].Properties, reflect.TypeOf(subVaporDocument{})},
+	}
+	for _, e := range cases {
+		for i := 0; i < e.Type.NumField(); i++ {
+			name := strings.Split(e.Type.Field(i).Tag.Get("json"), ",")[0]
+			if name == "-" || e.Type.Field(i).PkgPath != "" {
+				continue
+			}
+			if _, ok := e.Properties[name]; !ok {
+				t.Errorf("%s.%s is missing from the schema", e.Type, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package summer
+
+import "strings"
+
+// LoadOption configures how a configuration is turned into a Graph.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	sources  []PropertySource
+	profiles map[string]bool
+	validate bool
+}
+
+func newLoadOptions(opts []LoadOption) *loadOptions {
+	o := &loadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.sources == nil {
+		o.sources = defaultPropertySources()
+	}
+	if o.profiles == nil {
+		o.profiles = make(map[string]bool)
+		if active, ok := properties(o.sources).lookup(ProfilesProperty); ok {
+			for _, p := range strings.Split(active, ",") {
+				if p = strings.TrimSpace(p); p != "" {
+					o.profiles[p] = true
+				}
+			}
+		}
+	}
+	return o
+}
+
+// activeDews returns the dews of def enabled by the active profiles.
+func (o *loadOptions) activeDews(def *Rain, logger Logger) []DewDefinition {
+	dews := make([]DewDefinition, 0, len(def.Dews))
+	// A file imported under several active profiles yields its dews once.
+	seen := make(map[Position]bool)
+	for _, d := range def.Dews {
+		if !d.active(o.profiles) {
+			if logger != nil {
+				logger.Debugf("skipped dew %s#%s, profiles %v are inactive", d.Class, d.Id, d.Profiles)
+			}
+			continue
+		}
+		if d.File != "" {
+			if seen[d.Position] {
+				continue
+			}
+			seen[d.Position] = true
+		}
+		dews = append(dews, d)
+	}
+	return dews
+}
+
+// defaultPropertySources are the environment followed by the .env file of the
+// working directory, if there is one.
+func defaultPropertySources() []PropertySource {
+	sources := []PropertySource{EnvSource{}}
+	if dotenv, err := DotEnvSource(".env"); err == nil {
+		sources = append(sources, dotenv)
+	}
+	return sources
+}
+
+// WithPropertySources sets the sources used to resolve ${key:default}
+// placeholders and per field overrides. Earlier sources take precedence over
+// later ones. Without this option the environment is used, followed by the
+// .env file of the working directory; neither is modified.
+func WithPropertySources(sources ...PropertySource) LoadOption {
+	return func(o *loadOptions) {
+		o.sources = append(o.sources, sources...)
+	}
+}
+
+// ProfilesProperty is the property listing the active profiles, comma
+// separated, when WithProfiles isn't used.
+const ProfilesProperty = "SUMMER_PROFILES"
+
+// WithProfiles sets the active profiles. Dews restricted to other profiles
+// are neither instantiated nor provided to the Graph.
+func WithProfiles(profiles ...string) LoadOption {
+	return func(o *loadOptions) {
+		if o.profiles == nil {
+			o.profiles = make(map[string]bool)
+		}
+		for _, p := range profiles {
+			o.profiles[p] = true
+		}
+	}
+}
+
+// WithValidation checks the whole configuration against the registered types
+// before building it. Unknown classes and fields, values that don't convert,
+// missing or unassignable dews and ambiguous interfaces are all reported
+// together as ConfigErrors.
+func WithValidation() LoadOption {
+	return func(o *loadOptions) {
+		o.validate = true
+	}
+}
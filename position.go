@@ -0,0 +1,185 @@
+package summer
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// positionIndex maps the path of an element in a configuration document to
+// its position. Paths are made of the element names and their index among
+// siblings of the same name, e.g. "dew/0/vapor/2" for the third vapor of the
+// first dew. The root element has the empty path.
+type positionIndex map[string]Position
+
+func elementPath(path, name string, i int) string {
+	if path != "" {
+		path += "/"
+	}
+	return path + name + "/" + strconv.Itoa(i)
+}
+
+// lineIndex converts byte offsets into lines and columns.
+type lineIndex []int // Offsets of the line starts
+
+func newLineIndex(data []byte) lineIndex {
+	lines := lineIndex{0}
+	for i, b := range data {
+		if b == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+func (l lineIndex) position(offset int64) Position {
+	line := sort.Search(len(l), func(i int) bool { return int64(l[i]) > offset }) - 1
+	return Position{Line: line + 1, Column: int(offset) - l[line] + 1}
+}
+
+func xmlPositions(data []byte) positionIndex {
+	type element struct {
+		path     string
+		children map[string]int
+	}
+	index := make(positionIndex)
+	lines := newLineIndex(data)
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*element
+	for {
+		offset := d.InputOffset()
+		tok, err := d.Token()
+		if err != nil {
+			return index
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path := ""
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				path = elementPath(parent.path, t.Name.Local, parent.children[t.Name.Local])
+				parent.children[t.Name.Local]++
+			}
+			index[path] = lines.position(offset)
+			stack = append(stack, &element{path: path, children: make(map[string]int)})
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+func yamlPositions(data []byte) positionIndex {
+	index := make(positionIndex)
+	var walk func(n *yaml.Node, path string)
+	walk = func(n *yaml.Node, path string) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, path)
+			}
+		case yaml.AliasNode:
+			walk(n.Alias, path)
+		case yaml.MappingNode:
+			index[path] = Position{Line: n.Line, Column: n.Column}
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key, value := n.Content[i].Value, n.Content[i+1]
+				if value.Kind == yaml.SequenceNode {
+					for j, c := range value.Content {
+						walk(c, elementPath(path, key, j))
+					}
+				}
+			}
+		}
+	}
+	var root yaml.Node
+	if yaml.Unmarshal(data, &root) == nil {
+		walk(&root, "")
+	}
+	return index
+}
+
+func jsonPositions(data []byte) positionIndex {
+	index := make(positionIndex)
+	lines := newLineIndex(data)
+	d := json.NewDecoder(bytes.NewReader(data))
+	var walk func(path string) error
+	walk = func(path string) error {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+			return skipJSON(d, tok)
+		}
+		index[path] = lines.position(d.InputOffset() - 1)
+		for d.More() {
+			key, err := d.Token()
+			if err != nil {
+				return err
+			}
+			tok, err := d.Token()
+			if err != nil {
+				return err
+			}
+			if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+				if err := skipJSON(d, tok); err != nil {
+					return err
+				}
+				continue
+			}
+			for i := 0; d.More(); i++ {
+				if err := walk(elementPath(path, key.(string), i)); err != nil {
+					return err
+				}
+			}
+			if _, err := d.Token(); err != nil {
+				return err
+			}
+		}
+		_, err = d.Token()
+		return err
+	}
+	walk("")
+	return index
+}
+
+// skipJSON skips the rest of the value starting with tok.
+func skipJSON(d *json.Decoder, tok json.Token) error {
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+	for depth := 1; depth > 0; {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok = tok.(json.Delim); ok {
+			if delim == '{' || delim == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+	}
+	return nil
+}
+
+// errorPosition locates a decoding error, as far as the decoder tells.
+func errorPosition(err error, data []byte, filename string) Position {
+	var pos Position
+	switch e := err.(type) {
+	case *xml.SyntaxError:
+		pos.Line = e.Line
+	case *json.SyntaxError:
+		pos = newLineIndex(data).position(e.Offset)
+	case *json.UnmarshalTypeError:
+		pos = newLineIndex(data).position(e.Offset)
+	}
+	pos.File = filename
+	return pos
+}
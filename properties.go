@@ -0,0 +1,183 @@
+package summer
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// PropertySource provides the values of ${key:default} placeholders.
+type PropertySource interface {
+	Property(key string) (string, bool)
+}
+
+// EnvSource looks properties up in the environment. The key is prefixed with
+// Prefix, so EnvSource{Prefix: "APP_"} resolves ${PORT} from APP_PORT.
+type EnvSource struct {
+	Prefix string
+}
+
+func (s EnvSource) Property(key string) (string, bool) {
+	return os.LookupEnv(s.Prefix + key)
+}
+
+// MapSource looks properties up in a map.
+type MapSource map[string]string
+
+func (m MapSource) Property(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// FlagSource looks properties up in the flags of a FlagSet, which defaults to
+// flag.CommandLine. Only flags set on the command line are considered, so a
+// placeholder default isn't shadowed by a flag default.
+type FlagSource struct {
+	FlagSet *flag.FlagSet
+}
+
+func (s FlagSource) Property(key string) (value string, found bool) {
+	fs := s.FlagSet
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == key {
+			value, found = f.Value.String(), true
+		}
+	})
+	return value, found
+}
+
+// DotEnvSource reads a .env file. The process environment is left untouched.
+func DotEnvSource(filename string) (MapSource, error) {
+	m, err := godotenv.Read(filename)
+	if err != nil {
+		return nil, err
+	}
+	return MapSource(m), nil
+}
+
+// PropertiesFileSource reads a properties file made of key=value or
+// key: value lines. Blank lines and lines starting with # or ! are ignored.
+func PropertiesFileSource(filename string) (MapSource, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m := make(MapSource)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			return nil, fmt.Errorf("%s:%d: expected key=value", filename, n)
+		}
+		m[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type properties []PropertySource
+
+func (p properties) lookup(key string) (string, bool) {
+	for _, s := range p {
+		if value, ok := s.Property(key); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// vaporValue resolves the const value of a vapor. The <class>.<name>
+// property overrides the configured value, class being the short name of the
+// type of the dew, such as db.Config, whatever name the configuration uses.
+func (p properties) vaporValue(class string, v *VaporDefinition) (string, error) {
+	value := v.Value
+	if override, ok := p.lookup(class + "." + v.Name); ok && override != "" {
+		value = override
+	}
+	return p.resolve(value)
+}
+
+// tagValue resolves the const value set by the vapor tag of a field: the
+// property named by env if it's set, the value otherwise. As for vapors, the
+// <class>.<field> property overrides both.
+func (p properties) tagValue(class, field string, t *vaporTag) (string, error) {
+	if override, ok := p.lookup(class + "." + field); ok && override != "" {
+		return p.resolve(override)
+	}
+	if t.Env != "" {
+		if value, ok := p.lookup(t.Env); ok {
+			return value, nil
+		}
+		if !t.HasValue {
+			return "", fmt.Errorf("property %s isn't set", t.Env)
+		}
+	}
+	return p.resolve(t.Value)
+}
+
+// resolveList resolves the values of the elements of a list vapor, including
+// nested ones. On error it returns the element at fault.
+func (p properties) resolveList(list []VaporDefinition) ([]VaporDefinition, *VaporDefinition, error) {
+	resolved := make([]VaporDefinition, len(list))
+	for i := range list {
+		resolved[i] = list[i]
+		value, err := p.resolve(list[i].Value)
+		if err != nil {
+			return nil, &list[i], err
+		}
+		resolved[i].Value = value
+		if len(list[i].List) != 0 {
+			nested, element, err := p.resolveList(list[i].List)
+			if err != nil {
+				return nil, element, err
+			}
+			resolved[i].List = nested
+		}
+	}
+	return resolved, nil, nil
+}
+
+// resolve replaces every ${key} and ${key:default} placeholder in s.
+func (p properties) resolve(s string) (string, error) {
+	var buf strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			buf.WriteString(s)
+			return buf.String(), nil
+		}
+		j := strings.IndexByte(s[i:], '}')
+		if j < 0 {
+			return "", fmt.Errorf("unterminated placeholder in %q", s)
+		}
+		buf.WriteString(s[:i])
+		key, def, hasDef := s[i+2:i+j], "", false
+		if k := strings.IndexByte(key, ':'); k >= 0 {
+			key, def, hasDef = key[:k], key[k+1:], true
+		}
+		value, ok := p.lookup(key)
+		if !ok {
+			if !hasDef {
+				return "", fmt.Errorf("unresolved placeholder ${%s}", key)
+			}
+			value = def
+		}
+		buf.WriteString(value)
+		s = s[i+j+1:]
+	}
+}
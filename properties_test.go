@@ -0,0 +1,225 @@
+package summer
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProperties_Resolve(t *testing.T) {
+	props := properties{MapSource{"db.host": "db1", "db.port": "5432", "empty": ""}}
+	cases := []struct {
+		In    string
+		Out   string
+		Error bool
+	}{
+		{In: "plain", Out: "plain"},
+		{In: "${db.host}", Out: "db1"},
+		{In: "${db.host:localhost}", Out: "db1"},
+		{In: "${db.user:root}", Out: "root"},
+		{In: "${db.user:}", Out: ""},
+		{In: "${empty:default}", Out: ""},
+		{In: "${db.host}:${db.port}/${db.name:test}", Out: "db1:5432/test"},
+		{In: "${url:http://localhost}", Out: "http://localhost"},
+		{In: "${db.user}", Error: true},
+		{In: "${db.host", Error: true},
+	}
+	for _, e := range cases {
+		out, err := props.resolve(e.In)
+		if e.Error != (err != nil) {
+			t.Fatalf("unexpected error %v for case %+v", err, e)
+		}
+		if out != e.Out {
+			t.Fatalf(`found unexpected value "%s" for %+v`, out, e)
+		}
+	}
+}
+
+func TestProperties_Order(t *testing.T) {
+	props := properties{MapSource{"a": "first"}, MapSource{"a": "second", "b": "second"}}
+	if v, _ := props.lookup("a"); v != "first" {
+		t.Fail()
+	}
+	if v, _ := props.lookup("b"); v != "second" {
+		t.Fail()
+	}
+}
+
+func TestPropertiesFileSource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.properties")
+	data := "# comment\n! comment\n\ndb.host = db1\ndb.port: 5432\nurl=http://localhost:80\n"
+	if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := PropertiesFileSource(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source["db.host"] != "db1" || source["db.port"] != "5432" || source["url"] != "http://localhost:80" {
+		t.Fatalf("unexpected properties %v", source)
+	}
+	if _, err := PropertiesFileSource(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fail()
+	}
+}
+
+func TestDotEnvSource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := ioutil.WriteFile(filename, []byte("DB_HOST=db1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := DotEnvSource(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := source.Property("DB_HOST"); !ok || v != "db1" {
+		t.Fail()
+	}
+}
+
+func TestEnvSource_Prefix(t *testing.T) {
+	os.Setenv("SUMMER_TEST_PORT", "8080")
+	defer os.Unsetenv("SUMMER_TEST_PORT")
+	if v, ok := (EnvSource{Prefix: "SUMMER_TEST_"}).Property("PORT"); !ok || v != "8080" {
+		t.Fail()
+	}
+	if _, ok := (EnvSource{}).Property("PORT"); ok {
+		t.Fail()
+	}
+}
+
+func TestFlagSource(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("host", "localhost", "")
+	fs.Int("port", 80, "")
+	if err := fs.Parse([]string{"-port", "8080"}); err != nil {
+		t.Fatal(err)
+	}
+	source := FlagSource{FlagSet: fs}
+	if v, ok := source.Property("port"); !ok || v != "8080" {
+		t.Fail()
+	}
+	if _, ok := source.Property("host"); ok {
+		t.Fail()
+	}
+}
+
+func TestContainer_XMLPlaceholder(t *testing.T) {
+	con := new(Container)
+	con.Register(StructInlineTest{})
+	con.Register(StructAnswer{})
+	config := []byte(`
+<rain>
+<dew id="answer" class="summer.StructAnswer">
+<vapor name="Ans" value="${answer:1}" />
+</dew>
+<dew id="test" class="summer.StructInlineTest">
+<vapor name="List">
+	<vapor value="${first}" />
+	<vapor value="${second:two}" />
+</vapor>
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithPropertySources(MapSource{"answer": "666", "first": "one"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.GetDewByName("answer").Value.(*StructAnswer).Ans != 666 {
+		t.Fail()
+	}
+	test := app.GetDewByName("test").Value.(*StructInlineTest)
+	if test.List[0] != "one" || test.List[1] != "two" {
+		t.Fail()
+	}
+}
+
+func TestContainer_OverrideClassName(t *testing.T) {
+	con := new(Container)
+	con.RegisterAs("answer", StructAnswer{})
+	con.Register(TaggedLeaf{})
+	full, _ := typeName(reflect.TypeOf(StructAnswer{}))
+	leaf, _ := typeName(reflect.TypeOf(TaggedLeaf{}))
+	sources := WithPropertySources(MapSource{"summer.StructAnswer.Ans": "42", "summer.TaggedLeaf.Port": "9090"})
+	for _, class := range []string{"summer.StructAnswer", full, "answer"} {
+		config := []byte(fmt.Sprintf(`
+<rain>
+<dew id="answer" class="%s">
+<vapor name="Ans" value="1" />
+</dew>
+<dew id="leaf" class="%s" />
+</rain>
+`, class, leaf))
+		app, err := con.XMLConfigurationContainer(config, nil, sources, WithValidation())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ans := app.GetDewByName("answer").Value.(*StructAnswer).Ans; ans != 42 {
+			t.Fatalf("class %s: expected the override but got %d", class, ans)
+		}
+		if port := app.GetDewByName("leaf").Value.(*TaggedLeaf).Port; port != 9090 {
+			t.Fatalf("class %s: expected the tag override but got %d", class, port)
+		}
+	}
+}
+
+func TestContainer_XMLPlaceholder_Unresolved(t *testing.T) {
+	con := new(Container)
+	con.Register(StructAnswer{})
+	config := []byte(`
+<rain>
+<dew id="answer" class="summer.StructAnswer">
+<vapor name="Ans" value="${answer}" />
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithPropertySources(MapSource{}))
+	if app != nil || err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "answer") || !strings.Contains(err.Error(), "Ans") {
+		t.Fatalf("error doesn't name the dew and vapor: %s", err)
+	}
+}
+
+func TestContainer_PropertySourcesIsolated(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := ioutil.WriteFile(filename, []byte("SUMMER_TEST_ANSWER=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dotenv, err := DotEnvSource(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	con := new(Container)
+	con.Register(StructAnswer{})
+	config := []byte(`
+<rain>
+<dew id="answer" class="summer.StructAnswer">
+<vapor name="Ans" value="${SUMMER_TEST_ANSWER}" />
+</dew>
+</rain>
+`)
+	app1, err := con.XMLConfigurationContainer(config, nil, WithPropertySources(MapSource{"SUMMER_TEST_ANSWER": "2"}, dotenv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app2, err := con.XMLConfigurationContainer(config, nil, WithPropertySources(dotenv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app1.GetDewByName("answer").Value.(*StructAnswer).Ans != 2 {
+		t.Fail()
+	}
+	if app2.GetDewByName("answer").Value.(*StructAnswer).Ans != 1 {
+		t.Fail()
+	}
+	if _, ok := os.LookupEnv("SUMMER_TEST_ANSWER"); ok {
+		t.Fatal("the .env file leaked into the environment")
+	}
+}
@@ -1,24 +1,193 @@
 package summer
 
-import "reflect"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
 
 type Container struct {
-	rain map[string]reflect.Type
+	rain       map[string]reflect.Type                  // By full name, see typeName
+	short      map[string][]string                      // Full names by short name
+	aliases    map[string]reflect.Type                  // See Alias
+	factories  map[string]reflect.Value                 // By class name
+	bindings   map[reflect.Type]map[string]reflect.Type // See Bind
+	converters converters
+	conflicts  []error // See Conflicts
 }
 
-func (c *Container) Register(proto interface{}) {
+// typeName returns the full name of t, qualified by its import path such as
+// github.com/acme/db.Config, and its short name such as db.Config.
+func typeName(t reflect.Type) (full, short string) {
+	short = t.String()
+	if t.Name() == "" || t.PkgPath() == "" {
+		return short, short
+	}
+	return t.PkgPath() + "." + t.Name(), short
+}
+
+// Register registers the type of proto under its full name, such as
+// github.com/acme/db.Config. Its short name, such as db.Config, is an alias as
+// long as no other registered type has the same one. Register returns an error
+// when it does, and from then on only the full names can be used.
+func (c *Container) Register(proto interface{}) error {
+	reflectType := reflect.TypeOf(proto)
+	full, short := typeName(reflectType)
+	return c.register(reflectType, full, short)
+}
+
+func (c *Container) register(reflectType reflect.Type, full, short string) error {
 	if c.rain == nil {
 		c.rain = make(map[string]reflect.Type)
+		c.short = make(map[string][]string)
 	}
-	reflectType := reflect.TypeOf(proto)
-	c.rain[reflectType.String()] = reflectType
+	if existing, ok := c.rain[full]; ok && existing != reflectType {
+		return c.conflict(fmt.Errorf("class %s is already registered as %s", full, existing))
+	}
+	c.rain[full] = reflectType
+	if short == full {
+		return nil
+	}
+	for _, existing := range c.short[short] {
+		if existing == full {
+			return nil
+		}
+	}
+	c.short[short] = append(c.short[short], full)
+	if len(c.short[short]) > 1 {
+		return c.conflict(fmt.Errorf("short name %s of %s is already used by %s", short, full, c.short[short][0]))
+	}
+	return nil
+}
+
+// RegisterAs registers the type of proto like Register does, and name as an
+// alias of it, see Alias.
+func (c *Container) RegisterAs(name string, proto interface{}) error {
+	if err := c.Register(proto); err != nil {
+		return err
+	}
+	full, _ := typeName(reflect.TypeOf(proto))
+	return c.Alias(name, full)
+}
+
+// Alias makes alias another name of the registered class name, which may be
+// used in configurations instead. It returns an error when alias already names
+// another class.
+func (c *Container) Alias(alias, name string) error {
+	t := c.GetType(name)
+	if t == nil {
+		return c.classError(name)
+	}
+	return c.alias(alias, t)
+}
+
+func (c *Container) alias(alias string, t reflect.Type) error {
+	if existing := c.GetType(alias); existing != nil && existing != t {
+		return c.conflict(fmt.Errorf("alias %s of %s is already used by %s", alias, t, existing))
+	}
+	if c.factories[alias].IsValid() {
+		return c.conflict(fmt.Errorf("alias %s of %s is already used by a factory", alias, t))
+	}
+	if c.aliases == nil {
+		c.aliases = make(map[string]reflect.Type)
+	}
+	c.aliases[alias] = t
+	return nil
 }
 
 func (c *Container) GetType(name string) reflect.Type {
 	if c.rain == nil {
 		return nil
 	}
-	return c.rain[name]
+	if t, ok := c.rain[name]; ok {
+		return t
+	}
+	if t, ok := c.aliases[name]; ok {
+		return t
+	}
+	if fulls := c.short[name]; len(fulls) == 1 {
+		return c.rain[fulls[0]]
+	}
+	return nil
+}
+
+// conflict records err, a registration conflict, see Conflicts.
+func (c *Container) conflict(err error) error {
+	c.conflicts = append(c.conflicts, err)
+	return err
+}
+
+// Conflicts returns every registration conflict so far, such as two types
+// registered with the same short name or alias. The registration functions
+// return them too, but they're easily ignored in init functions.
+func (c *Container) Conflicts() []error {
+	return c.conflicts
+}
+
+// Merge registers everything other registers into c: types, aliases,
+// factories, implementations and converters. It goes on past conflicts and
+// returns them all at once.
+func (c *Container) Merge(other *Container) error {
+	var errs []error
+	shorts := make(map[string]string)
+	for short, fulls := range other.short {
+		for _, full := range fulls {
+			shorts[full] = short
+		}
+	}
+	for _, full := range sortedKeys(other.rain) {
+		short, ok := shorts[full]
+		if !ok {
+			short = full
+		}
+		if err := c.register(other.rain[full], full, short); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, alias := range sortedKeys(other.aliases) {
+		if err := c.alias(alias, other.aliases[alias]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, name := range sortedKeys(other.factories) {
+		if err := c.registerFactory(name, other.factories[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, c.mergeBindings(other)...)
+	types := make([]reflect.Type, 0, len(other.converters))
+	for t := range other.converters {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+	for _, t := range types {
+		fn := other.converters[t]
+		if existing, ok := c.converters[t]; ok && reflect.ValueOf(existing).Pointer() != reflect.ValueOf(fn).Pointer() {
+			errs = append(errs, c.conflict(fmt.Errorf("converter for type %s is already registered", t)))
+			continue
+		}
+		c.RegisterConverter(t, fn)
+	}
+	return errors.Join(errs...)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// classError reports why name isn't a registered class.
+func (c *Container) classError(name string) error {
+	if fulls := c.short[name]; len(fulls) > 1 {
+		return fmt.Errorf("class %s is ambiguous, use one of %s", name, strings.Join(fulls, ", "))
+	}
+	return fmt.Errorf("class %s doesn't exist", name)
 }
 
 func (c *Container) Get(name string) interface{} {
@@ -29,6 +198,20 @@ func (c *Container) Get(name string) interface{} {
 	return reflect.New(reflectType).Interface()
 }
 
+// GetMap returns the registered types by every name they can be used with:
+// full name, unambiguous short name and alias.
 func (c *Container) GetMap() map[string]reflect.Type {
-	return c.rain
+	m := make(map[string]reflect.Type, len(c.rain)+len(c.short)+len(c.aliases))
+	for name, fulls := range c.short {
+		if len(fulls) == 1 {
+			m[name] = c.rain[fulls[0]]
+		}
+	}
+	for name, t := range c.aliases {
+		m[name] = t
+	}
+	for name, t := range c.rain {
+		m[name] = t
+	}
+	return m
 }
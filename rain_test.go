@@ -1,6 +1,8 @@
 package summer
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -36,3 +38,132 @@ func TestContainer_Get(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestContainer_RegisterFullName(t *testing.T) {
+	con := new(Container)
+	if err := con.Register(test_server{}); err != nil {
+		t.Fatal(err)
+	}
+	full := reflect.TypeOf(test_server{}).PkgPath() + ".test_server"
+	if con.GetType(full) == nil || con.GetMap()[full] == nil {
+		t.Fatalf("%s isn't registered", full)
+	}
+	if err := con.Register(test_server{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContainer_RegisterShortNameCollision(t *testing.T) {
+	con := new(Container)
+	if err := con.register(reflect.TypeOf(test_server{}), "github.com/a/summer.test_server", "summer.test_server"); err != nil {
+		t.Fatal(err)
+	}
+	err := con.register(reflect.TypeOf(StructAnswer{}), "github.com/b/summer.test_server", "summer.test_server")
+	const msg = "short name summer.test_server of github.com/b/summer.test_server is already used by github.com/a/summer.test_server"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+	if con.GetType("summer.test_server") != nil || con.GetMap()["summer.test_server"] != nil {
+		t.Fatal("the ambiguous short name is still registered")
+	}
+	if con.GetType("github.com/b/summer.test_server") != reflect.TypeOf(StructAnswer{}) {
+		t.Fatal("the full name isn't registered")
+	}
+
+	config := []byte(`
+<rain>
+<dew class="summer.test_server" />
+</rain>
+`)
+	_, err = con.XMLConfigurationContainer(config, nil)
+	if err == nil || !strings.Contains(err.Error(), "class summer.test_server is ambiguous, use one of github.com/a/summer.test_server, github.com/b/summer.test_server") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestContainer_RegisterAs(t *testing.T) {
+	con := new(Container)
+	if err := con.RegisterAs("answer-store", StructAnswer{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.Alias("answer", "answer-store"); err != nil {
+		t.Fatal(err)
+	}
+	con.Register(test_server{})
+	answer := reflect.TypeOf(StructAnswer{})
+	for _, name := range []string{"answer-store", "answer", "summer.StructAnswer"} {
+		if con.GetType(name) != answer || con.GetMap()[name] != answer {
+			t.Fatalf("%s doesn't name summer.StructAnswer", name)
+		}
+	}
+	if err := con.Alias("answer", "summer.test_server"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := con.Alias("summer.test_server", "answer"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := con.Alias("server", "missing"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	config := []byte(`
+<rain>
+<dew id="answer" class="answer">
+<vapor name="Ans" value="666" />
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.GetDewByName("answer").Value.(*StructAnswer).Ans != 666 {
+		t.Fail()
+	}
+}
+
+func TestContainer_Merge(t *testing.T) {
+	a := new(Container)
+	a.Register(StructAnswer{})
+	a.RegisterAs("server", test_server{})
+	b := new(Container)
+	b.Register(StructAnswer{})
+	b.RegisterAs("answer", StructAnswer{})
+	b.register(reflect.TypeOf(StructInlineTest{}), "github.com/b/summer.test_server", "summer.test_server")
+	b.RegisterAs("server", StructInlineTest{})
+	if err := b.RegisterFactory("client", NewFactoryClient); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Bind((*BindCache)(nil), "memory", MemoryCache{}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := a.Merge(b)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	msgs := []string{
+		"short name summer.test_server of github.com/b/summer.test_server is already used by",
+		"alias server of summer.StructInlineTest is already used by summer.test_server",
+	}
+	for _, msg := range msgs {
+		if !strings.Contains(err.Error(), msg) {
+			t.Fatalf("%q doesn't report %q", err, msg)
+		}
+	}
+	if len(a.Conflicts()) != 2 {
+		t.Fatalf("unexpected conflicts %v", a.Conflicts())
+	}
+	if a.GetType("answer") != reflect.TypeOf(StructAnswer{}) || a.GetType("summer.StructInlineTest") == nil {
+		t.Fatal("the types weren't merged")
+	}
+	if _, ok := a.factories["client"]; !ok {
+		t.Fatal("the factory wasn't merged")
+	}
+	if a.Implementations("summer.BindCache")["memory"] == nil {
+		t.Fatal("the implementation wasn't merged")
+	}
+	if a.GetType("server") != reflect.TypeOf(test_server{}) {
+		t.Fatal("the conflicting alias was overwritten")
+	}
+}
@@ -0,0 +1,56 @@
+package summer
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Resolve returns the value of the Dew assignable to T. Unnamed Dews are
+// looked up first, then named ones, the primary ones winning ties. It's an
+// error if there isn't exactly one.
+func Resolve[T any](g *Graph) (T, error) {
+	var zero T
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	found := g.lookup(t)
+	switch len(found) {
+	case 0:
+		return zero, fmt.Errorf("found no assignable value for type %s", t)
+	case 1:
+		return found[0].Value.(T), nil
+	}
+	return zero, fmt.Errorf("found two assignable values for type %s: %s and %s", t, found[0], found[1])
+}
+
+// ResolveNamed returns the value of the Dew named name, which must be
+// assignable to T.
+func ResolveNamed[T any](g *Graph, name string) (T, error) {
+	var zero T
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	existing := g.GetDewByName(name)
+	if existing == nil {
+		return zero, fmt.Errorf("did not find object named %s", name)
+	}
+	value, ok := existing.Value.(T)
+	if !ok {
+		return zero, fmt.Errorf("object named %s of type %s is not assignable to %s", name, existing.reflectType, t)
+	}
+	return value, nil
+}
+
+// ResolveAll returns the values of every Dew assignable to T, named or not,
+// in the order of auto slices: by Order, then by name. Private Dews are left
+// out.
+func ResolveAll[T any](g *Graph) []T {
+	var found []*Dew
+	for _, o := range g.Objects() {
+		if _, ok := o.Value.(T); ok && !o.private {
+			found = append(found, o)
+		}
+	}
+	sortDews(found)
+	values := make([]T, len(found))
+	for i, o := range found {
+		values[i] = o.Value.(T)
+	}
+	return values
+}
@@ -0,0 +1,56 @@
+package summer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	var g Graph
+	a := TypeAnswerStruct{answer: 1}
+	b := TypeOtherAnswer{}
+	named := TypeAnswerStruct{answer: 2}
+	if err := g.Provide(&Dew{Value: &a}, &Dew{Value: &b}, &Dew{Value: &named, Name: "named"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := Resolve[*TypeAnswerStruct](&g); err != nil || v != &a {
+		t.Fatalf("unexpected %v, %v", v, err)
+	}
+	if _, err := Resolve[Answerable](&g); err == nil || !strings.HasPrefix(err.Error(), "found two assignable values for type summer.Answerable") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := Resolve[*TypeNestedStruct](&g); err == nil || err.Error() != "found no assignable value for type *summer.TypeNestedStruct" {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if v, err := ResolveNamed[Answerable](&g, "named"); err != nil || v != &named {
+		t.Fatalf("unexpected %v, %v", v, err)
+	}
+	if _, err := ResolveNamed[*TypeOtherAnswer](&g, "named"); err == nil || !strings.Contains(err.Error(), "is not assignable to *summer.TypeOtherAnswer") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := ResolveNamed[Answerable](&g, "missing"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	all := ResolveAll[Answerable](&g)
+	if len(all) != 3 || all[0] != &a || all[1] != &b || all[2] != &named {
+		t.Fatalf("unexpected %v", all)
+	}
+}
+
+func TestResolvePrimary(t *testing.T) {
+	var g Graph
+	a := TypeAnswerStruct{}
+	b := TypeOtherAnswer{}
+	if err := g.Provide(&Dew{Value: &a}, &Dew{Value: &b, Primary: true}); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Resolve[Answerable](&g); err != nil || v != &b {
+		t.Fatalf("unexpected %v, %v", v, err)
+	}
+}
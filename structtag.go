@@ -2,7 +2,10 @@ package summer
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
 	"strconv"
+	"strings"
 )
 
 var errInvalidTag = errors.New("invalid tag")
@@ -58,3 +61,169 @@ func extract(name, tag string) (found bool, value string, err error) {
 	}
 	return false, "", nil
 }
+
+// vaporTag is a parsed vapor struct tag. The tag is a comma separated list of
+// the flags auto, optional, private and named and of the key=value options
+// name, qualifier, value and env, for instance `vapor:"name=db"`,
+// `vapor:"qualifier=read"` or `vapor:"value=${port:8080}"`.
+type vaporTag struct {
+	Auto      bool   // Inject an unnamed dew
+	Optional  bool   // Tolerate a missing dew
+	Private   bool   // Inject a new dew
+	Named     bool   // Also collect the named dews into an auto slice
+	Name      string // Inject the dew with this id
+	Qualifier string // Inject the dew carrying this label
+	Value     string // Const value, may hold ${key:default} placeholders
+	HasValue  bool   // Whether Value was set, as it may be empty
+	Env       string // Property holding the const value, before Value
+}
+
+// parseVaporTag parses the value of a vapor tag.
+func parseVaporTag(tag string) (*vaporTag, error) {
+	t := &vaporTag{}
+	if tag == "" {
+		return t, nil
+	}
+	seen := make(map[string]bool)
+	for _, option := range splitVaporTag(tag) {
+		key, value, hasValue := strings.Cut(option, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("empty option in %q", tag)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate option %s", key)
+		}
+		seen[key] = true
+		switch key {
+		case "auto", "optional", "private", "named":
+			if hasValue {
+				return nil, fmt.Errorf("option %s doesn't take a value", key)
+			}
+		case "name", "qualifier", "value", "env":
+			if !hasValue {
+				return nil, fmt.Errorf("option %s expects a value", key)
+			}
+		default:
+			return nil, fmt.Errorf("unknown option %s", key)
+		}
+		switch key {
+		case "auto":
+			t.Auto = true
+		case "optional":
+			t.Optional = true
+		case "private":
+			t.Private = true
+		case "named":
+			t.Named = true
+		case "name":
+			if value == "" {
+				return nil, fmt.Errorf("option name expects a dew id")
+			}
+			t.Name = value
+		case "qualifier":
+			if value == "" {
+				return nil, fmt.Errorf("option qualifier expects a label")
+			}
+			t.Qualifier = value
+		case "value":
+			t.Value, t.HasValue = value, true
+		case "env":
+			if value == "" {
+				return nil, fmt.Errorf("option env expects a property name")
+			}
+			t.Env = value
+		}
+	}
+	kinds := 0
+	for _, set := range []bool{t.Auto, t.Private, t.Name != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds > 1 {
+		return nil, fmt.Errorf("options auto, private and name are exclusive")
+	}
+	if t.Qualifier != "" && (t.Private || t.Name != "") {
+		return nil, fmt.Errorf("option qualifier can't be combined with private or name")
+	}
+	if t.isConst() && (kinds != 0 || t.Optional || t.Named || t.Qualifier != "") {
+		return nil, fmt.Errorf("options value and env can't be combined with an injection")
+	}
+	return t, nil
+}
+
+// splitVaporTag splits a vapor tag on the commas outside of placeholders.
+func splitVaporTag(tag string) []string {
+	var options []string
+	depth, start := 0, 0
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case strings.HasPrefix(tag[i:], "${"):
+			depth++
+			i++
+		case tag[i] == '}' && depth > 0:
+			depth--
+		case tag[i] == ',' && depth == 0:
+			options = append(options, tag[start:i])
+			start = i + 1
+		}
+	}
+	return append(options, tag[start:])
+}
+
+// isConst reports whether the tag sets a const value.
+func (t *vaporTag) isConst() bool {
+	return t.HasValue || t.Env != ""
+}
+
+// option returns the injection option declared by the tag, if any.
+func (t *vaporTag) option() (Option, bool) {
+	if !t.Auto && !t.Optional && !t.Private && !t.Named && t.Name == "" && t.Qualifier == "" {
+		return Option{}, false
+	}
+	return Option{
+		Name:      t.Name,
+		Private:   t.Private,
+		Optional:  t.Optional,
+		Named:     t.Named,
+		Qualifier: t.Qualifier,
+	}, true
+}
+
+// vaporTags returns the vapor tags of the fields of struct type t by field
+// name. Fields without a vapor tag are left out, even if their tag is
+// malformed otherwise.
+func vaporTags(t reflect.Type) (map[string]*vaporTag, error) {
+	tags := make(map[string]*vaporTag)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		found, value, err := extract("vapor", string(field.Tag))
+		if err == errInvalidTag && !strings.Contains(string(field.Tag), "vapor:") {
+			continue
+		}
+		if err == nil && found {
+			tags[field.Name], err = parseVaporTag(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid vapor tag on field %s in type %s: %w", field.Name, t, err)
+		}
+	}
+	return tags, nil
+}
+
+// tagOptions returns the injection options declared by the vapor tags of the
+// fields of struct type t.
+func tagOptions(t reflect.Type) (map[string]Option, error) {
+	tags, err := vaporTags(t)
+	if err != nil {
+		return nil, err
+	}
+	options := make(map[string]Option)
+	for name, tag := range tags {
+		if option, ok := tag.option(); ok {
+			options[name] = option
+		}
+	}
+	return options, nil
+}
@@ -1,6 +1,7 @@
 package summer
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -74,3 +75,92 @@ func TestExtract(t *testing.T) {
 		}
 	}
 }
+
+func TestParseVaporTag(t *testing.T) {
+	cases := []struct {
+		Tag   string    // Input tag
+		Parse *vaporTag // Expected tag, nil if an error is expected
+	}{
+		{Tag: "", Parse: &vaporTag{}},
+		{Tag: "auto", Parse: &vaporTag{Auto: true}},
+		{Tag: "auto,optional", Parse: &vaporTag{Auto: true, Optional: true}},
+		{Tag: "private", Parse: &vaporTag{Private: true}},
+		{Tag: "name=db", Parse: &vaporTag{Name: "db"}},
+		{Tag: "name=db, optional", Parse: &vaporTag{Name: "db", Optional: true}},
+		{Tag: "value=${port:8080}", Parse: &vaporTag{Value: "${port:8080}", HasValue: true}},
+		{Tag: "value=${list:a,b},env=LIST", Parse: &vaporTag{Value: "${list:a,b}", HasValue: true, Env: "LIST"}},
+		{Tag: "value=", Parse: &vaporTag{HasValue: true}},
+		{Tag: "env=PORT", Parse: &vaporTag{Env: "PORT"}},
+		{Tag: "qualifier=read", Parse: &vaporTag{Qualifier: "read"}},
+		{Tag: "auto,qualifier=read,optional", Parse: &vaporTag{Auto: true, Qualifier: "read", Optional: true}},
+		{Tag: "qualifier="},
+		{Tag: "name=db,qualifier=read"},
+		{Tag: "private,qualifier=read"},
+		{Tag: "autos"},
+		{Tag: "auto,"},
+		{Tag: "auto,auto"},
+		{Tag: "auto=true"},
+		{Tag: "name"},
+		{Tag: "name="},
+		{Tag: "env="},
+		{Tag: "auto,name=db"},
+		{Tag: "private,name=db"},
+		{Tag: "auto,value=1"},
+		{Tag: "optional,env=PORT"},
+	}
+
+	for _, e := range cases {
+		tag, err := parseVaporTag(e.Tag)
+		if e.Parse == nil {
+			if err == nil {
+				t.Fatalf("did not get expected error for tag %q", e.Tag)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %s for tag %q", err, e.Tag)
+		}
+		if *tag != *e.Parse {
+			t.Fatalf("found unexpected %+v for tag %q", *tag, e.Tag)
+		}
+	}
+}
+
+func TestVaporTagsError(t *testing.T) {
+	type invalid struct {
+		A int `vapor:"value=1"`
+		B int `vapor:"auto,value=1"`
+	}
+	_, err := vaporTags(reflect.TypeOf(invalid{}))
+	const msg = "invalid vapor tag on field B in type summer.invalid: options value and env can't be combined with an injection"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+}
+
+func TestVaporTagsMalformed(t *testing.T) {
+	// The tags are built at run time, vet rejects malformed tag literals.
+	malformed := reflect.StructOf([]reflect.StructField{
+		{Name: "A", Type: reflect.TypeOf(0), Tag: `json:"a" legacy`},
+		{Name: "B", Type: reflect.TypeOf(0), Tag: `vapor:"value=1"`},
+	})
+	tags, err := vaporTags(malformed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tags["A"]; ok || tags["B"] == nil {
+		t.Fatalf("unexpected %v", tags)
+	}
+
+	var g Graph
+	if err := g.Provide(&Dew{Value: reflect.New(malformed).Interface()}); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := reflect.StructOf([]reflect.StructField{
+		{Name: "A", Type: reflect.TypeOf(0), Tag: `legacy vapor:"auto"`},
+	})
+	if _, err := vaporTags(invalid); err == nil {
+		t.Fatal("expected an error")
+	}
+}
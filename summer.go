@@ -2,45 +2,33 @@ package summer
 
 import (
 	"encoding"
-	"encoding/xml"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"net/url"
 	"reflect"
 	"strconv"
-	"os"
-	"github.com/joho/godotenv"
-
+	"strings"
+	"time"
 )
 
 var UMARSHALTEXT_TYPE = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
 
-type xmlSubVapor struct {
-	Name  string `xml:"name,attr"`
-	Dew   string `xml:"dew,attr"`
-	Value string `xml:"value,attr"`
-}
-
-type xmlVapor struct {
-	Name    string        `xml:"name,attr"`
-	Dew     string        `xml:"dew,attr"`
-	Value   string        `xml:"value,attr"`
-	Private bool          `xml:"private,attr"`
-	Auto    bool          `xml:"auto,attr"`
-	List    []xmlSubVapor `xml:"vapor"`
-}
-
-type xmlDew struct {
-	Id    string     `xml:"id,attr"`
-	Class string     `xml:"class,attr"`
-	Vapor []xmlVapor `xml:"vapor"`
-}
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	urlType      = reflect.TypeOf(url.URL{})
+	stringType   = reflect.TypeOf("")
+)
 
-type xmlRain struct {
-	XMLName xml.Name `xml:"rain"`
-	Dew     []xmlDew `xml:"dew"`
+func setFieldWithString(v reflect.Value, value string) error {
+	return converters(nil).setField(v, value, "")
 }
 
-func setFieldWithString(v reflect.Value, value string) error {
+// setField sets v from a string. A registered converter for the type of v
+// comes first, then the built-in conversions honoring the hints in the tag of
+// the field: `layout:"2006-01-02"` for time.Time, which defaults to RFC 3339,
+// and `unit:"bytes"` for integers holding sizes like 64MiB.
+func (cv converters) setField(v reflect.Value, value string, tag reflect.StructTag) error {
 	if !v.IsValid() {
 		return fmt.Errorf("invalid field with value %s", value)
 	}
@@ -48,39 +36,122 @@ func setFieldWithString(v reflect.Value, value string) error {
 	if !v.CanSet() {
 		return fmt.Errorf("field of type %s can't set", kt.String())
 	}
+	if ok, err := cv.convert(v, value); ok {
+		return err
+	}
+	if kt.Kind() == reflect.Ptr {
+		// Allocate pointers, keeping the current value if there is one.
+		elem := reflect.New(kt.Elem())
+		if !v.IsNil() {
+			elem.Elem().Set(v.Elem())
+		}
+		if err := cv.setField(elem.Elem(), value, tag); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	switch kt {
+	case durationType:
+		// A value without a unit is a number of nanoseconds.
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			n, nerr := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if nerr != nil {
+				return err
+			}
+			d = time.Duration(n)
+		}
+		v.SetInt(int64(d))
+		return nil
+	case timeType:
+		layout := tag.Get("layout")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case urlType:
+		u, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(u).Elem())
+		return nil
+	}
+	if reflect.PtrTo(kt).Implements(UMARSHALTEXT_TYPE) {
+		fv := reflect.New(kt)
+		if err := fv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return err
+		}
+		v.Set(fv.Elem())
+		return nil
+	}
 	switch v.Kind() {
 	case reflect.String:
 		v.Set(reflect.ValueOf(value).Convert(kt))
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		n, err := strconv.ParseInt(value, 10, 64)
+		if tag.Get("unit") == "bytes" {
+			n, err := parseByteSize(value)
+			if err != nil {
+				return err
+			}
+			if n > 1<<(uint(kt.Bits())-1)-1 {
+				return fmt.Errorf("size %s overflows type %s", value, kt.String())
+			}
+			v.SetInt(int64(n))
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 10, kt.Bits())
 		if err != nil {
 			return err
 		}
-		v.Set(reflect.ValueOf(n).Convert(kt))
+		v.SetInt(n)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		n, err := strconv.ParseUint(value, 10, 64)
+		if tag.Get("unit") == "bytes" {
+			n, err := parseByteSize(value)
+			if err != nil {
+				return err
+			}
+			if v.OverflowUint(n) {
+				return fmt.Errorf("size %s overflows type %s", value, kt.String())
+			}
+			v.SetUint(n)
+			return nil
+		}
+		n, err := strconv.ParseUint(value, 10, kt.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, kt.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Complex64, reflect.Complex128:
+		n, err := strconv.ParseComplex(value, kt.Bits())
 		if err != nil {
 			return err
 		}
-		v.Set(reflect.ValueOf(n).Convert(kt))
+		v.SetComplex(n)
 	case reflect.Bool:
 		n, err := strconv.ParseBool(value)
 		if err != nil {
 			return err
 		}
 		v.SetBool(n)
-	case reflect.Struct:
-		if reflect.PtrTo(kt).Implements(UMARSHALTEXT_TYPE) {
-			fv := reflect.New(kt)
-			if itm, ok := fv.Interface().(encoding.TextUnmarshaler); ok {
-				if err := itm.UnmarshalText([]byte(value)); err != nil {
-					return err
-				}
-				v.Set(fv.Elem())
-				return nil
-			}
+	case reflect.Interface:
+		// Only interfaces a string satisfies, such as interface{}.
+		if !stringType.Implements(kt) {
+			return fmt.Errorf("invalid inject %s into type %s", value, kt.String())
 		}
-		return fmt.Errorf("invalid inject %s into type %s", value, kt.String())
+		v.Set(reflect.ValueOf(value))
 	default:
 		return fmt.Errorf("invalid inject %s into type %s", value, kt.String())
 	}
@@ -88,22 +159,85 @@ func setFieldWithString(v reflect.Value, value string) error {
 }
 
 func setStructField(s interface{}, fieldName string, value string) error {
+	return converters(nil).setStructField(s, fieldName, value)
+}
+
+func (cv converters) setStructField(s interface{}, fieldName string, value string) error {
 	if !isStructPtr(reflect.TypeOf(s)) {
 		return fmt.Errorf("need a struct")
 	}
 	v := reflect.ValueOf(s).Elem().FieldByName(fieldName)
-	return setFieldWithString(v, value)
+	field, _ := reflect.TypeOf(s).Elem().FieldByName(fieldName)
+	return cv.setField(v, value, field.Tag)
+}
+
+var byteUnits = map[string]uint64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// parseByteSize parses sizes like 512, 64MiB or 1.5GB. KB, MB... are powers
+// of 1000 and KiB, MiB... powers of 1024.
+func parseByteSize(value string) (uint64, error) {
+	s := strings.TrimSpace(value)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	unit, ok := byteUnits[strings.ToUpper(strings.TrimSpace(s[i:]))]
+	if !ok || i == 0 {
+		return 0, fmt.Errorf("invalid byte size %s", value)
+	}
+	if !strings.Contains(s[:i], ".") {
+		n, err := strconv.ParseUint(s[:i], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		if n > (1<<64-1)/unit {
+			return 0, fmt.Errorf("byte size %s is too large", value)
+		}
+		return n * unit, nil
+	}
+	f, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, err
+	}
+	if f*float64(unit) >= 1<<64 {
+		return 0, fmt.Errorf("byte size %s is too large", value)
+	}
+	return uint64(f * float64(unit)), nil
+}
+
+func setStructInlineField(s interface{}, fieldName string, list []VaporDefinition) error {
+	return converters(nil).setStructInlineField(s, fieldName, list)
 }
 
-func setStructInlineField(s interface{}, fieldName string, list []xmlSubVapor) error {
+func (cv converters) setStructInlineField(s interface{}, fieldName string, list []VaporDefinition) error {
 	if !isStructPtr(reflect.TypeOf(s)) {
 		return fmt.Errorf("need a struct")
 	}
 	v := reflect.ValueOf(s).Elem().FieldByName(fieldName)
-	kt := v.Type()
 	if !v.IsValid() {
 		return fmt.Errorf("invalid field %s", fieldName)
 	}
+	field, _ := reflect.TypeOf(s).Elem().FieldByName(fieldName)
+	return cv.setList(v, fieldName, field.Tag, list)
+}
+
+// setList sets a list, array or map from its elements, or the fields of a
+// nested struct from the vapors naming them.
+func (cv converters) setList(v reflect.Value, fieldName string, tag reflect.StructTag, list []VaporDefinition) error {
+	kt := v.Type()
 	if !v.CanSet() {
 		return fmt.Errorf("field %s can't set", fieldName)
 	}
@@ -111,7 +245,7 @@ func setStructInlineField(s interface{}, fieldName string, list []xmlSubVapor) e
 	case reflect.Slice:
 		l := reflect.MakeSlice(kt, len(list), len(list))
 		for i := range list {
-			if err := setFieldWithString(l.Index(i), list[i].Value); err != nil {
+			if err := cv.setElement(l.Index(i), fieldName, tag, &list[i]); err != nil {
 				return err
 			}
 		}
@@ -121,7 +255,7 @@ func setStructInlineField(s interface{}, fieldName string, list []xmlSubVapor) e
 			return fmt.Errorf("the length of %s doesn't match array %s", fieldName, kt.String())
 		}
 		for i := range list {
-			if err := setFieldWithString(v.Index(i), list[i].Value); err != nil {
+			if err := cv.setElement(v.Index(i), fieldName, tag, &list[i]); err != nil {
 				return err
 			}
 		}
@@ -130,15 +264,37 @@ func setStructInlineField(s interface{}, fieldName string, list []xmlSubVapor) e
 		for i := range list {
 			valueKey := reflect.New(kt.Key()).Elem()
 			valueVal := reflect.New(kt.Elem()).Elem()
-			if err := setFieldWithString(valueKey, list[i].Name); err != nil {
+			if err := cv.setField(valueKey, list[i].Name, ""); err != nil {
 				return err
 			}
-			if err := setFieldWithString(valueVal, list[i].Value); err != nil {
+			if err := cv.setElement(valueVal, fieldName, tag, &list[i]); err != nil {
 				return err
 			}
 			l.SetMapIndex(valueKey, valueVal)
 		}
 		v.Set(l)
+	case reflect.Ptr:
+		if kt.Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("unsupported type %s", kt.String())
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(kt.Elem()))
+		}
+		return cv.setList(v.Elem(), fieldName, tag, list)
+	case reflect.Struct:
+		for i := range list {
+			name := list[i].Name
+			if name == "" {
+				return fmt.Errorf("expected a vapor name in %s", fieldName)
+			}
+			field, ok := kt.FieldByName(name)
+			if !ok {
+				return fmt.Errorf("unknown field %s in %s (%s)", name, fieldName, kt.String())
+			}
+			if err := cv.setElement(v.FieldByIndex(field.Index), fieldName+"."+name, field.Tag, &list[i]); err != nil {
+				return err
+			}
+		}
 	default:
 		return fmt.Errorf("unsupported type %s", kt.String())
 
@@ -146,84 +302,184 @@ func setStructInlineField(s interface{}, fieldName string, list []xmlSubVapor) e
 	return nil
 }
 
-func (c *Container) XMLConfigurationContainer(data []byte, logger Logger) (*Graph, error) {
-	var r xmlRain
-	godotenv.Load()
-	app := &Graph{Logger: logger}
+// setElement sets an element of a list, array, map or nested struct, which
+// is either a const value or made of nested vapors itself.
+func (cv converters) setElement(v reflect.Value, fieldName string, tag reflect.StructTag, element *VaporDefinition) error {
+	if element.Dew != "" {
+		return fmt.Errorf("can't inject dew %s into %s", element.Dew, fieldName)
+	}
+	if len(element.List) != 0 {
+		return cv.setList(v, fieldName, tag, element.List)
+	}
+	return cv.setField(v, element.Value, tag)
+}
+
+// tagValues assigns the const values set by the vapor tags of t, the class of
+// a dew, unless the configuration sets the field itself. The injections the
+// tags declare are handled by Graph.Provide.
+func (c *Container) tagValues(d *DewDefinition, t reflect.Type, object interface{}, props properties, debug func(string, ...interface{})) error {
+	tags, err := vaporTags(t)
+	if err != nil {
+		return dewError(d, err)
+	}
+	explicit := make(map[string]bool)
+	for j := range d.Vapors {
+		explicit[d.Vapors[j].Name] = true
+	}
+	_, class := typeName(t)
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		tag, ok := tags[name]
+		if !ok || !tag.isConst() || explicit[name] {
+			continue
+		}
+		value, err := props.tagValue(class, name, tag)
+		if err == nil {
+			err = c.converters.setStructField(object, name, value)
+		}
+		if err != nil {
+			return vaporError(d, &VaporDefinition{Name: name}, err)
+		}
+		debug("assigned %s to field %s in %s", value, name, d.Class)
+	}
+	return nil
+}
+
+// populateConfigError locates err, returned by Populate, at the dew or vapor
+// at fault when it's one of defs.
+func populateConfigError(defs map[*Dew]*DewDefinition, err error) error {
+	var pe *populateError
+	if !errors.As(err, &pe) {
+		return err
+	}
+	d, ok := defs[pe.dew]
+	if !ok {
+		return err
+	}
+	if pe.field == "" {
+		return dewError(d, pe.err)
+	}
+	for j := range d.Vapors {
+		if d.Vapors[j].Name == pe.field {
+			return vaporError(d, &d.Vapors[j], pe.err)
+		}
+	}
+	return vaporError(d, &VaporDefinition{Name: pe.field}, pe.err)
+}
+
+// Build instantiates the dews of a configuration and populates them. Every
+// configuration format is decoded into a Rain and resolved here, so the
+// formats can't drift apart.
+func (c *Container) Build(def *Rain, logger Logger, opts ...LoadOption) (*Graph, error) {
+	o := newLoadOptions(opts)
+	props := properties(o.sources)
+	app := &Graph{Logger: logger, PropertySources: o.sources, Converters: c.converters}
 	debug := func(f string, args ...interface{}) {
 		if logger != nil {
 			logger.Debugf(f, args...)
 		}
 	}
-	if err := xml.Unmarshal(data, &r); err != nil {
+	dews := o.activeDews(def, logger)
+	if o.validate {
+		if err := c.validate(dews, props); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkDuplicates(dews); err != nil {
 		return nil, err
 	}
-	for _, d := range r.Dew {
-		// Instantiate objects
-		object := c.Get(d.Class)
-		oType := c.GetType(d.Class)
-		if object == nil {
-			return nil, fmt.Errorf("dew %s#%s doesn't exist", d.Class, d.Id)
+	defs := make(map[*Dew]*DewDefinition, len(dews))
+	named := make(map[string]bool)
+	for _, d := range dews {
+		if d.Id != "" {
+			named[d.Id] = true
 		}
-		options := make(map[string]Option)
-		// Tag
-		for i := 0; i < oType.NumField(); i++ {
-			found, value, err := extract("vapor", string(oType.Field(i).Tag))
-			if err != nil {
-				continue
+	}
+	for i := range dews {
+		d := &dews[i]
+		if fn, ok := c.factories[d.Class]; ok {
+			if len(d.Vapors) != 0 {
+				return nil, vaporError(d, &d.Vapors[0], fmt.Errorf("a factory dew doesn't take vapors"))
 			}
-			if !found {
-				continue
+			first := &Dew{
+				Name:       d.Id,
+				Order:      d.Order,
+				Primary:    d.Primary,
+				Qualifiers: d.Qualifiers,
 			}
-			switch value {
-			case "auto":
-				options[oType.Field(i).Name] = Option{Name: ""}
+			if err := app.provideFunc(fn.Interface(), first); err != nil {
+				return nil, dewError(d, err)
 			}
+			continue
+		}
+		// Instantiate objects
+		oType, err := c.classType(d)
+		if err != nil {
+			return nil, dewError(d, err)
 		}
-		// Vapor config from XML
-		for _, v := range d.Vapor {
+		object := reflect.New(oType).Interface()
+		if err := c.tagValues(d, oType, object, props, debug); err != nil {
+			return nil, err
+		}
+		_, class := typeName(oType)
+		options := make(map[string]Option)
+		// Vapor config
+		for j := range d.Vapors {
+			v := &d.Vapors[j]
 			// Inject arguments
 			if v.Name == "" {
-				return nil, fmt.Errorf("expected a vapor name at dew %s#%s", d.Class, d.Id)
+				return nil, vaporError(d, v, fmt.Errorf("expected a vapor name"))
 			}
 			if v.Dew != "" {
 				if len(v.List) != 0 {
-					return nil, fmt.Errorf("dew %s#%s shouldn't be a list or a map", d.Class, d.Id)
+					return nil, vaporError(d, v, fmt.Errorf("a dew shouldn't be a list or a map"))
+				}
+				if !named[v.Dew] && !v.Optional {
+					return nil, vaporError(d, v, fmt.Errorf("did not find dew named %s", v.Dew))
 				}
 				// Inject a named dew
-				options[v.Name] = Option{Name: v.Dew}
+				options[v.Name] = Option{Name: v.Dew, Optional: v.Optional}
 			} else {
-				if v.Auto {
+				if v.Auto || v.Private || v.Optional || v.Named || v.Qualifier != "" {
 					if len(v.List) != 0 {
-						return nil, fmt.Errorf("auto vapor at dew %s#%s shouldn't be a list or a map", d.Class, d.Id)
+						return nil, vaporError(d, v, fmt.Errorf("auto vapor shouldn't be a list or a map"))
+					}
+					// Inject a unnamed dew, or a new one for private vapors
+					options[v.Name] = Option{
+						Private:   v.Private,
+						Optional:  v.Optional,
+						Named:     v.Named,
+						Qualifier: v.Qualifier,
 					}
-					// Inject a unnamed dew
-					options[v.Name] = Option{Name: ""}
 				} else {
 					if len(v.List) == 0 {
 						// Inject const value
-						value := v.Value
-						if os.Getenv(d.Class+"."+v.Name) != "" {
-							value = os.Getenv(d.Class+"."+v.Name)
+						value, err := props.vaporValue(class, v)
+						if err != nil {
+							return nil, vaporError(d, v, err)
 						}
-						if err := setStructField(object, v.Name, value); err != nil {
-							return nil, err
+						if err := c.converters.setStructField(object, v.Name, value); err != nil {
+							return nil, vaporError(d, v, err)
 						}
 						debug(
 							"assigned %s to field %s in %s",
-							v.Value,
+							value,
 							v.Name,
 							d.Class,
 						)
 					} else {
 						if v.List[0].Dew == "" {
-							// Inject const list/map
-							if err := setStructInlineField(object, v.Name, v.List); err != nil {
-								return nil, err
+							// Inject const list/map/struct
+							list, element, err := props.resolveList(v.List)
+							if err != nil {
+								return nil, elementError(d, v, element, err)
+							}
+							if err := c.converters.setStructInlineField(object, v.Name, list); err != nil {
+								return nil, vaporError(d, v, err)
 							}
 							debug(
 								"assigned %s to field %s in %s",
-								v.List,
+								list,
 								v.Name,
 								d.Class,
 							)
@@ -231,6 +487,9 @@ func (c *Container) XMLConfigurationContainer(data []byte, logger Logger) (*Grap
 							// Inject dew
 							vaporOp := make([]VaporOption, len(v.List))
 							for i := range v.List {
+								if !named[v.List[i].Dew] {
+									return nil, elementError(d, v, &v.List[i], fmt.Errorf("did not find dew named %s", v.List[i].Dew))
+								}
 								vaporOp[i].Name = v.List[i].Name
 								vaporOp[i].Dew = v.List[i].Dew
 							}
@@ -240,25 +499,22 @@ func (c *Container) XMLConfigurationContainer(data []byte, logger Logger) (*Grap
 				}
 			}
 		}
-		err := app.Provide(&Dew{
-			Value:   object,
-			Name:    d.Id,
-			Options: options,
-		})
-		if err != nil {
-			return nil, err
+		o := &Dew{
+			Value:      object,
+			Name:       d.Id,
+			Order:      d.Order,
+			Primary:    d.Primary,
+			Qualifiers: d.Qualifiers,
+			Options:    options,
+			valued:     true,
 		}
+		if err := app.Provide(o); err != nil {
+			return nil, dewError(d, err)
+		}
+		defs[o] = d
 	}
 	if err := app.Populate(); err != nil {
-		return nil, err
+		return nil, populateConfigError(defs, err)
 	}
 	return app, nil
 }
-
-func (c *Container) XMLFileConfigurationContainer(filename string, logger Logger) (*Graph, error) {
-	data, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return nil, err
-	}
-	return c.XMLConfigurationContainer(data, logger)
-}
@@ -3,7 +3,11 @@ package summer
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 )
 
 type StructAnswer struct {
@@ -398,3 +402,317 @@ func TestContainer_AutoInject_Tag(t *testing.T) {
 	}
 	app.Stop(context.Background())
 }
+
+type test_number_struct struct {
+	Float32    float32
+	Float64    float64
+	Complex    complex128
+	Int8       int8
+	Timeout    time.Duration
+	Time       time.Time
+	Date       time.Time `layout:"2006-01-02"`
+	Size       int64     `unit:"bytes"`
+	USize      uint32    `unit:"bytes"`
+	Timeouts   []time.Duration
+	Dates      []time.Time `layout:"2006-01-02"`
+	SmallSize  int8        `unit:"bytes"`
+	PlainBytes int64
+}
+
+func TestContainer_SetField_Numbers(t *testing.T) {
+	var s test_number_struct
+	cases := []struct {
+		Field string
+		Value string
+	}{
+		{"Float32", "1.5"},
+		{"Float64", "-2.25e3"},
+		{"Complex", "(1+2i)"},
+		{"Timeout", "5s"},
+		{"Time", "2018-11-02T10:00:00Z"},
+		{"Date", "2018-11-02"},
+		{"Size", "64MiB"},
+		{"USize", "1.5 KB"},
+	}
+	for _, e := range cases {
+		if err := setStructField(&s, e.Field, e.Value); err != nil {
+			t.Fatalf("unexpected error %s for %+v", err, e)
+		}
+	}
+	if s.Float32 != 1.5 || s.Float64 != -2250 || s.Complex != complex(1, 2) {
+		t.Fatalf("unexpected numbers %+v", s)
+	}
+	if s.Timeout != 5*time.Second {
+		t.Fatalf("unexpected duration %s", s.Timeout)
+	}
+	if err := setStructField(&s, "Timeout", "5000000000"); err != nil || s.Timeout != 5*time.Second {
+		t.Fatalf("unexpected duration %s in nanoseconds: %v", s.Timeout, err)
+	}
+	if !s.Time.Equal(time.Date(2018, 11, 2, 10, 0, 0, 0, time.UTC)) || !s.Date.Equal(time.Date(2018, 11, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected times %s %s", s.Time, s.Date)
+	}
+	if s.Size != 64<<20 || s.USize != 1500 {
+		t.Fatalf("unexpected sizes %d %d", s.Size, s.USize)
+	}
+}
+
+func TestContainer_SetField_NumbersBad(t *testing.T) {
+	var s test_number_struct
+	cases := []struct {
+		Field string
+		Value string
+	}{
+		{"Float32", "abc"},
+		{"Complex", "1+"},
+		{"Int8", "300"},
+		{"Timeout", "5 parsecs"},
+		{"Time", "2018-11-02"},
+		{"Date", "2018-11-02T10:00:00Z"},
+		{"Size", "64XB"},
+		{"Size", "MiB"},
+		{"SmallSize", "1KiB"},
+		{"PlainBytes", "1KiB"},
+	}
+	for _, e := range cases {
+		if err := setStructField(&s, e.Field, e.Value); err == nil {
+			t.Fatalf("expected an error for %+v", e)
+		}
+	}
+}
+
+func TestContainer_XMLInjectDurationList(t *testing.T) {
+	con := new(Container)
+	con.Register(test_number_struct{})
+	config := []byte(`
+<rain>
+<dew id="test" class="summer.test_number_struct">
+<vapor name="Timeout" value="1m30s" />
+<vapor name="Timeouts">
+	<vapor value="1s" />
+	<vapor value="250ms" />
+</vapor>
+<vapor name="Dates">
+	<vapor value="2018-11-02" />
+</vapor>
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := app.GetDewByName("test").Value.(*test_number_struct)
+	if test.Timeout != 90*time.Second || test.Timeouts[1] != 250*time.Millisecond || test.Dates[0].Day() != 2 {
+		t.Fatalf("unexpected values %+v", test)
+	}
+}
+
+type test_pool struct {
+	Max     int
+	Idle    *int
+	Timeout time.Duration
+}
+
+type test_pointer_struct struct {
+	Int     *int
+	Str     *string
+	Ans     *StructUmarshalTestAns
+	IP      net.IP
+	Any     interface{}
+	URLs    []*url.URL
+	Pool    test_pool
+	Backup  *test_pool
+	Pools   map[string]test_pool
+	Answers []*StructUmarshalTestAns
+}
+
+func TestContainer_SetField_Pointers(t *testing.T) {
+	var s test_pointer_struct
+	if err := setStructField(&s, "Int", "-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setStructField(&s, "Str", "str"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setStructField(&s, "Ans", "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setStructField(&s, "IP", "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setStructField(&s, "Any", "any"); err != nil {
+		t.Fatal(err)
+	}
+	if *s.Int != -1 || *s.Str != "str" || s.Ans.A != 666 || !s.IP.Equal(net.IPv4(127, 0, 0, 1)) || s.Any != "any" {
+		t.Fatalf("unexpected values %+v", s)
+	}
+	if setStructField(&s, "Int", "abc") == nil || setStructField(&s, "IP", "abc") == nil {
+		t.Fail()
+	}
+	if setStructField(&s, "Pool", "abc") == nil {
+		t.Fail()
+	}
+}
+
+func TestContainer_XMLInjectNested(t *testing.T) {
+	con := new(Container)
+	con.Register(test_pointer_struct{})
+	config := []byte(`
+<rain>
+<dew id="test" class="summer.test_pointer_struct">
+<vapor name="URLs">
+	<vapor value="http://localhost:8080/a" />
+	<vapor value="https://example.com" />
+</vapor>
+<vapor name="Pool">
+	<vapor name="Max" value="${max:10}" />
+	<vapor name="Idle" value="2" />
+</vapor>
+<vapor name="Backup">
+	<vapor name="Timeout" value="5s" />
+</vapor>
+<vapor name="Pools">
+	<vapor name="read">
+		<vapor name="Max" value="3" />
+	</vapor>
+</vapor>
+<vapor name="Answers">
+	<vapor value="test" />
+</vapor>
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithPropertySources(MapSource{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := app.GetDewByName("test").Value.(*test_pointer_struct)
+	if len(test.URLs) != 2 || test.URLs[0].Port() != "8080" || test.URLs[1].Host != "example.com" {
+		t.Fatalf("unexpected urls %v", test.URLs)
+	}
+	if test.Pool.Max != 10 || *test.Pool.Idle != 2 {
+		t.Fatalf("unexpected pool %+v", test.Pool)
+	}
+	if test.Backup == nil || test.Backup.Timeout != 5*time.Second {
+		t.Fatalf("unexpected backup %+v", test.Backup)
+	}
+	if test.Pools["read"].Max != 3 || test.Answers[0].A != 666 {
+		t.Fatalf("unexpected values %+v", test)
+	}
+}
+
+func TestContainer_XMLInjectNested_Bad(t *testing.T) {
+	con := new(Container)
+	con.Register(test_pointer_struct{})
+	config := []byte(`
+<rain>
+<dew id="test" class="summer.test_pointer_struct">
+<vapor name="Pool">
+	<vapor name="Min" value="1" />
+</vapor>
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil)
+	if app != nil || err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+type TaggedServer struct {
+	Host   string        `vapor:"value=${host:localhost}"`
+	Port   int           `vapor:"env=PORT,value=8080"`
+	Answer *StructAnswer `vapor:"name=answer"`
+}
+
+func TestContainer_TagGrammar(t *testing.T) {
+	con := new(Container)
+	con.Register(TaggedServer{})
+	con.Register(StructAnswer{})
+	config := []byte(`
+<rain>
+<dew id="answer" class="summer.StructAnswer">
+<vapor name="Ans" value="666" />
+</dew>
+<dew id="server" class="summer.TaggedServer">
+<vapor name="Host" value="example.com" />
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithPropertySources(MapSource{"PORT": "9090"}), WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := app.GetDewByName("server").Value.(*TaggedServer)
+	if server.Host != "example.com" || server.Port != 9090 || server.Answer.Ans != 666 {
+		t.Fatalf("unexpected %+v", server)
+	}
+
+	app, err = con.XMLConfigurationContainer(config, nil, WithPropertySources(MapSource{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.GetDewByName("server").Value.(*TaggedServer).Port != 8080 {
+		t.Fail()
+	}
+}
+
+type TaggedLeaf struct {
+	Port int `vapor:"value=8080"`
+}
+
+type TaggedRoot struct {
+	Leaf    *TaggedLeaf `vapor:"auto"`
+	Private *TaggedLeaf `vapor:"private"`
+}
+
+func TestContainer_TagValueCreated(t *testing.T) {
+	con := new(Container)
+	con.Register(TaggedRoot{})
+	config := []byte(`
+<rain>
+<dew id="root" class="summer.TaggedRoot" />
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithPropertySources(MapSource{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := app.GetDewByName("root").Value.(*TaggedRoot)
+	if root.Leaf.Port != 8080 || root.Private.Port != 8080 {
+		t.Fatalf("unexpected %+v and %+v", root.Leaf, root.Private)
+	}
+
+	sources := WithPropertySources(MapSource{"summer.TaggedLeaf.Port": "9090"})
+	app, err = con.XMLConfigurationContainer(config, nil, sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.GetDewByName("root").Value.(*TaggedRoot).Leaf.Port != 9090 {
+		t.Fail()
+	}
+}
+
+type MalformedTag struct {
+	Answer *StructAnswer `vapor:"auto,name=answer"`
+}
+
+func TestContainer_MalformedTag(t *testing.T) {
+	con := new(Container)
+	con.Register(MalformedTag{})
+	config := []byte(`
+<rain>
+<dew class="summer.MalformedTag" />
+</rain>
+`)
+	const msg = "invalid vapor tag on field Answer in type summer.MalformedTag"
+	for _, opts := range [][]LoadOption{nil, {WithValidation()}} {
+		app, err := con.XMLConfigurationContainer(config, nil, opts...)
+		if app != nil || err == nil {
+			t.Fatal("expected an error")
+		}
+		if !strings.Contains(err.Error(), msg) {
+			t.Fatalf("unexpected error %s", err)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package summer
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Validate checks a configuration against the registered types without
+// building it: nothing is instantiated beyond scratch values for parsing
+// consts, Populate isn't called and nothing is started. Only the dews of the
+// active profiles are checked. Every problem is reported in ConfigErrors.
+func (c *Container) Validate(def *Rain, opts ...LoadOption) error {
+	o := newLoadOptions(opts)
+	return c.validate(o.activeDews(def, nil), properties(o.sources))
+}
+
+// candidate is a dew an auto inject may choose. d is nil for the dews
+// Populate creates.
+type candidate struct {
+	t reflect.Type
+	d *DewDefinition
+}
+
+func (c candidate) hasQualifier(qualifier string) bool {
+	if c.d == nil {
+		return false
+	}
+	for _, q := range c.d.Qualifiers {
+		if q == qualifier {
+			return true
+		}
+	}
+	return false
+}
+
+// validator checks a configuration against the registered types, collecting
+// every problem instead of stopping at the first one. It only works on types
+// and scratch values: no dew is provided to a Graph or started.
+type validator struct {
+	c       *Container
+	props   properties
+	dews    []DewDefinition
+	types   []reflect.Type   // Pointer types of the dews, nil for unknown classes
+	results [][]reflect.Type // Results of the factory dews
+	named   map[string]int
+	unnamed []candidate // Including the dews Populate will create
+	errs    ConfigErrors
+}
+
+func (c *Container) validate(dews []DewDefinition, props properties) error {
+	v := &validator{
+		c:       c,
+		props:   props,
+		dews:    dews,
+		types:   make([]reflect.Type, len(dews)),
+		results: make([][]reflect.Type, len(dews)),
+		named:   make(map[string]int),
+	}
+	v.checkDews()
+	v.checkCreated()
+	for i := range dews {
+		if v.results[i] != nil {
+			if len(dews[i].Vapors) != 0 {
+				v.add(vaporError(&dews[i], &dews[i].Vapors[0], fmt.Errorf("a factory dew doesn't take vapors")))
+			}
+		} else if v.types[i] != nil {
+			v.checkVapors(i)
+		}
+	}
+	if len(v.errs) != 0 {
+		return v.errs
+	}
+	return nil
+}
+
+func (v *validator) add(err error) {
+	v.errs = append(v.errs, err)
+}
+
+// checkDews checks the classes and ids of the dews.
+func (v *validator) checkDews() {
+	unnamed := make(map[reflect.Type]bool)
+	for i := range v.dews {
+		d := &v.dews[i]
+		if d.Id != "" {
+			if first, ok := v.named[d.Id]; ok {
+				v.add(dewError(d, fmt.Errorf("id %s is already defined at %s", d.Id, v.dews[first].Position)))
+			} else {
+				v.named[d.Id] = i
+			}
+		}
+		if fn, ok := v.c.factories[d.Class]; ok {
+			f := &factory{fn: fn}
+			v.results[i] = f.results()
+			v.types[i] = v.results[i][0]
+			if d.Id == "" {
+				v.unnamed = append(v.unnamed, candidate{v.types[i], d})
+				for _, result := range v.results[i][1:] {
+					v.unnamed = append(v.unnamed, candidate{t: result})
+				}
+			}
+			continue
+		}
+		t, err := v.c.classType(d)
+		if err != nil {
+			v.add(dewError(d, err))
+			continue
+		}
+		if t.Kind() != reflect.Struct {
+			v.add(dewError(d, fmt.Errorf("class %s isn't a struct", d.Class)))
+			continue
+		}
+		v.types[i] = reflect.PtrTo(t)
+		if d.Id == "" {
+			if unnamed[v.types[i]] {
+				v.add(dewError(d, fmt.Errorf("provided two unnamed instances of type %s", t)))
+			}
+			unnamed[v.types[i]] = true
+			v.unnamed = append(v.unnamed, candidate{v.types[i], d})
+		}
+	}
+}
+
+// checkCreated adds the types Populate creates for auto injected pointer
+// fields to the unnamed dews, as they may satisfy interface fields.
+func (v *validator) checkCreated() {
+	for i := range v.dews {
+		if v.types[i] == nil || v.results[i] != nil {
+			continue
+		}
+		for _, field := range v.autoFields(i) {
+			// Private dews are never injected anywhere else and optional or
+			// qualified ones aren't created.
+			if field.private || field.optional || field.qualifier != "" || !isStructPtr(field.Type) ||
+				len(v.assignable(field)) != 0 {
+				continue
+			}
+			v.unnamed = append(v.unnamed, candidate{t: field.Type})
+		}
+	}
+}
+
+// autoField is a field receiving an unnamed dew.
+type autoField struct {
+	reflect.StructField
+	private   bool
+	optional  bool
+	qualifier string
+}
+
+// autoFields returns the fields receiving an unnamed dew, either through a
+// vapor tag or an auto or private vapor.
+func (v *validator) autoFields(i int) []autoField {
+	t := v.types[i].Elem()
+	explicit := make(map[string]bool)
+	var fields []autoField
+	for _, vapor := range v.dews[i].Vapors {
+		explicit[vapor.Name] = true
+		if field, ok := t.FieldByName(vapor.Name); ok && (vapor.Auto || vapor.Private || vapor.Optional || vapor.Named || vapor.Qualifier != "") && vapor.Dew == "" {
+			fields = append(fields, autoField{field, vapor.Private, vapor.Optional, vapor.Qualifier})
+		}
+	}
+	// Malformed tags are reported by checkTags.
+	options, _ := tagOptions(t)
+	for j := 0; j < t.NumField(); j++ {
+		field := t.Field(j)
+		if option, ok := options[field.Name]; ok && option.Name == "" && !explicit[field.Name] {
+			fields = append(fields, autoField{field, option.Private, option.Optional, option.Qualifier})
+		}
+	}
+	return fields
+}
+
+// assignable returns the dews an auto inject may assign to field, preferring
+// the primary ones as Populate does. See Graph.assignable.
+func (v *validator) assignable(field autoField) []candidate {
+	var found []candidate
+	if field.qualifier == "" {
+		for _, u := range v.unnamed {
+			if u.t.AssignableTo(field.Type) {
+				found = append(found, u)
+			}
+		}
+	} else {
+		for i := range v.dews {
+			u := candidate{v.types[i], &v.dews[i]}
+			if u.t != nil && u.t.AssignableTo(field.Type) && u.hasQualifier(field.qualifier) {
+				found = append(found, u)
+			}
+		}
+	}
+	var primaries []candidate
+	for _, u := range found {
+		if u.d != nil && u.d.Primary {
+			primaries = append(primaries, u)
+		}
+	}
+	if len(primaries) != 0 {
+		return primaries
+	}
+	return found
+}
+
+func (v *validator) checkVapors(i int) {
+	d := &v.dews[i]
+	t := v.types[i].Elem()
+	scratch := reflect.New(t).Interface()
+	_, class := typeName(t)
+	v.checkTags(d, t, scratch)
+	for j := range d.Vapors {
+		vapor := &d.Vapors[j]
+		if vapor.Name == "" {
+			v.add(vaporError(d, vapor, fmt.Errorf("expected a vapor name")))
+			continue
+		}
+		field, ok := t.FieldByName(vapor.Name)
+		if !ok {
+			v.add(vaporError(d, vapor, fmt.Errorf("unknown field %s in type %s", vapor.Name, t)))
+			continue
+		}
+		if field.PkgPath != "" {
+			v.add(vaporError(d, vapor, fmt.Errorf("field %s in type %s is unexported", vapor.Name, t)))
+			continue
+		}
+		switch {
+		case vapor.Dew != "":
+			if len(vapor.List) != 0 {
+				v.add(vaporError(d, vapor, fmt.Errorf("a dew shouldn't be a list or a map")))
+				continue
+			}
+			if _, ok := v.named[vapor.Dew]; !ok && vapor.Optional {
+				continue
+			}
+			if err := v.checkRef(vapor.Dew, field.Type); err != nil {
+				v.add(vaporError(d, vapor, err))
+			}
+		case vapor.Auto || vapor.Private || vapor.Optional || vapor.Named || vapor.Qualifier != "":
+			if len(vapor.List) != 0 {
+				v.add(vaporError(d, vapor, fmt.Errorf("auto vapor shouldn't be a list or a map")))
+			}
+		case len(vapor.List) == 0:
+			value, err := v.props.vaporValue(class, vapor)
+			if err == nil {
+				err = v.c.converters.setStructField(scratch, vapor.Name, value)
+			}
+			if err != nil {
+				v.add(vaporError(d, vapor, err))
+			}
+		case vapor.List[0].Dew == "":
+			list, element, err := v.props.resolveList(vapor.List)
+			if err != nil {
+				v.add(elementError(d, vapor, element, err))
+				continue
+			}
+			if err := v.c.converters.setStructInlineField(scratch, vapor.Name, list); err != nil {
+				v.add(vaporError(d, vapor, err))
+			}
+		default:
+			v.checkRefList(d, vapor, field.Type)
+		}
+	}
+	for _, field := range v.autoFields(i) {
+		if err := v.checkAuto(field); err != nil {
+			v.add(&ConfigError{Position: d.Position, Dew: d.Class + "#" + d.Id, Vapor: field.Name, Err: err})
+		}
+	}
+}
+
+// checkTags checks the vapor tags of type t, including the dews they refer to
+// and the const values they set, unless the dew configures the field.
+func (v *validator) checkTags(d *DewDefinition, t reflect.Type, scratch interface{}) {
+	tags, err := vaporTags(t)
+	if err != nil {
+		v.add(dewError(d, err))
+		return
+	}
+	explicit := make(map[string]bool)
+	for j := range d.Vapors {
+		explicit[d.Vapors[j].Name] = true
+	}
+	_, class := typeName(t)
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		tag, ok := tags[name]
+		if !ok || explicit[name] {
+			continue
+		}
+		if tag.Name != "" {
+			if _, ok := v.named[tag.Name]; ok || !tag.Optional {
+				if err := v.checkRef(tag.Name, t.Field(i).Type); err != nil {
+					v.add(vaporError(d, &VaporDefinition{Name: name}, err))
+				}
+			}
+			continue
+		}
+		if !tag.isConst() {
+			continue
+		}
+		value, err := v.props.tagValue(class, name, tag)
+		if err == nil {
+			err = v.c.converters.setStructField(scratch, name, value)
+		}
+		if err != nil {
+			v.add(vaporError(d, &VaporDefinition{Name: name}, err))
+		}
+	}
+}
+
+// checkRef checks that the dew named name can be injected into type t.
+func (v *validator) checkRef(name string, t reflect.Type) error {
+	i, ok := v.named[name]
+	if !ok {
+		return fmt.Errorf("did not find dew named %s", name)
+	}
+	if v.types[i] != nil && !v.types[i].AssignableTo(t) {
+		return fmt.Errorf("dew named %s of type %s is not assignable to %s", name, v.types[i], t)
+	}
+	return nil
+}
+
+func (v *validator) checkRefList(d *DewDefinition, vapor *VaporDefinition, t reflect.Type) {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Map {
+		v.add(vaporError(d, vapor, fmt.Errorf("expected a slice or a map, got %s", t)))
+		return
+	}
+	for k := range vapor.List {
+		element := &vapor.List[k]
+		if t.Kind() == reflect.Map {
+			key := reflect.New(t.Key()).Elem()
+			if err := v.c.converters.setField(key, element.Name, ""); err != nil {
+				v.add(elementError(d, vapor, element, err))
+			}
+		}
+		if err := v.checkRef(element.Dew, t.Elem()); err != nil {
+			v.add(elementError(d, vapor, element, err))
+		}
+	}
+}
+
+// checkAuto checks that an unnamed dew can be injected into field.
+func (v *validator) checkAuto(field autoField) error {
+	if field.private {
+		if !isStructPtr(field.Type) {
+			return fmt.Errorf("found private inject option on unsupported field %s", field.Name)
+		}
+		return nil
+	}
+	switch {
+	case field.Type.Kind() == reflect.Interface:
+		found := v.assignable(field)
+		switch len(found) {
+		case 0:
+			if field.optional {
+				return nil
+			}
+			return fmt.Errorf("found no assignable value for field %s", field.Name)
+		case 1:
+			return nil
+		}
+		return fmt.Errorf("found two assignable values for field %s: %s and %s", field.Name, found[0].t, found[1].t)
+	case isStructPtr(field.Type) && field.qualifier != "":
+		found := v.assignable(field)
+		if len(found) > 1 {
+			return fmt.Errorf("found two assignable values for field %s: %s and %s", field.Name, found[0].t, found[1].t)
+		}
+		if len(found) == 0 && !field.optional {
+			return fmt.Errorf("found no assignable value qualified %s for field %s", field.qualifier, field.Name)
+		}
+		return nil
+	case field.Type.Kind() == reflect.Map && field.Type.Key().Kind() != reflect.String:
+		return fmt.Errorf("expected string keys for auto map field %s", field.Name)
+	case isStructPtr(field.Type), field.Type.Kind() == reflect.Slice, field.Type.Kind() == reflect.Map:
+		return nil
+	}
+	return fmt.Errorf("found inject option on unsupported field %s", field.Name)
+}
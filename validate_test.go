@@ -0,0 +1,175 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type ValidateAmbiguous struct {
+	Answer Answerable `vapor:"auto"`
+}
+
+func TestContainer_WithValidation(t *testing.T) {
+	con := new(Container)
+	con.Register(AnswerSpeaker{})
+	con.Register(StructAnswer{})
+	con.Register(StructInlineTest{})
+	con.Register(StructInlineDewTest{})
+	con.Register(StructUmarshalTest{})
+	con.Register(ValidateAmbiguous{})
+	config := []byte(`<rain>
+<dew class="summer.Unknown" />
+<dew id="answer" class="summer.StructAnswer">
+	<vapor name="Ans" value="abc" />
+	<vapor name="Missing" value="1" />
+</dew>
+<dew id="checker" class="summer.AnswerSpeaker">
+	<vapor name="Answer" dew="nobody" />
+</dew>
+<dew id="list" class="summer.StructInlineDewTest">
+	<vapor name="List">
+		<vapor dew="list" />
+		<vapor dew="answer" />
+	</vapor>
+</dew>
+<dew id="inline" class="summer.StructInlineTest">
+	<vapor name="Array">
+		<vapor value="1" />
+	</vapor>
+</dew>
+<dew class="summer.StructAnswer" />
+<dew class="summer.StructUmarshalTest" />
+<dew id="ambiguous" class="summer.ValidateAmbiguous" />
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithValidation())
+	if app != nil {
+		t.Fatal("expected no graph")
+	}
+	var errs ConfigErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	expected := []string{
+		"2:1: dew summer.Unknown#: class summer.Unknown doesn't exist",
+		"4:2: dew summer.StructAnswer#answer, vapor Ans: ",
+		"5:2: dew summer.StructAnswer#answer, vapor Missing: unknown field Missing",
+		"8:2: dew summer.AnswerSpeaker#checker, vapor Answer: did not find dew named nobody",
+		"12:3: dew summer.StructInlineDewTest#list, vapor List: dew named list of type *summer.StructInlineDewTest is not assignable",
+		"17:2: dew summer.StructInlineTest#inline, vapor Array: the length of Array doesn't match",
+		"23:1: dew summer.ValidateAmbiguous#ambiguous, vapor Answer: found two assignable values",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %s", len(expected), err)
+	}
+	for i, e := range expected {
+		if !strings.Contains(errs[i].Error(), e) {
+			t.Fatalf("expected %q, got %q", e, errs[i])
+		}
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Fatal("expected the causes to be wrapped")
+	}
+}
+
+func TestContainer_WithValidation_Build(t *testing.T) {
+	con := new(Container)
+	con.Register(AnswerSpeakerTag{})
+	con.Register(StructAnswer{})
+	con.Register(StructInlineDewTest{})
+	config := []byte(`
+<rain>
+<dew class="summer.StructAnswer">
+<vapor name="Ans" value="666" />
+</dew>
+<dew id="checker" class="summer.AnswerSpeakerTag" />
+<dew id="list" class="summer.StructInlineDewTest">
+<vapor name="Map">
+	<vapor name="key" dew="checker" />
+</vapor>
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithValidation())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "of type *summer.AnswerSpeakerTag is not assignable") {
+		t.Fatal(err)
+	}
+	config = []byte(`
+<rain>
+<dew class="summer.StructAnswer">
+<vapor name="Ans" value="666" />
+</dew>
+<dew id="checker" class="summer.AnswerSpeakerTag" />
+</rain>
+`)
+	app, err = con.XMLConfigurationContainer(config, nil, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.GetDewByName("checker").Value.(*AnswerSpeakerTag).Answer.Answer() != 666 {
+		t.Fail()
+	}
+}
+
+var validateStarted bool
+
+type ValidateStarter struct {
+	Answer Answerable `vapor:"auto"`
+	Port   int
+}
+
+func (s *ValidateStarter) Start(ctx context.Context) error {
+	validateStarted = true
+	return nil
+}
+
+func TestContainer_ValidateXML(t *testing.T) {
+	con := new(Container)
+	con.Register(ValidateStarter{})
+	con.Register(StructAnswer{})
+	config := []byte(`
+<rain>
+<dew class="summer.StructAnswer" profile="prod" />
+<dew id="starter" class="summer.ValidateStarter">
+<vapor name="Port" value="${port}" />
+</dew>
+</rain>
+`)
+	if err := con.ValidateXML(config, WithProfiles("prod"), WithPropertySources(MapSource{"port": "8080"})); err != nil {
+		t.Fatal(err)
+	}
+	err := con.ValidateXML(config, WithProfiles("dev"), WithPropertySources(MapSource{"port": "http"}))
+	var errs ConfigErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", err)
+	}
+	if validateStarted {
+		t.Fatal("validation started a dew")
+	}
+}
+
+func TestContainer_ValidateFormats(t *testing.T) {
+	con := new(Container)
+	con.Register(AnswerSpeaker{})
+	con.Register(StructAnswer{})
+	con.Register(StructInlineDewTest{})
+	if err := con.ValidateXMLFile("testdata/import/main.xml"); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.ValidateYAMLFile("testdata/import/answer.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.ValidateJSON([]byte(`{"dew": [{"class": "summer.AnswerSpeaker", "vapor": [{"name": "Answer", "dew": "x"}]}]}`)); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := con.ValidateYAML([]byte("dew: [")); err == nil {
+		t.Fatal("expected an error")
+	}
+}
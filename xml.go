@@ -0,0 +1,19 @@
+package summer
+
+// XMLConfigurationContainer builds a Graph from an XML configuration.
+func (c *Container) XMLConfigurationContainer(data []byte, logger Logger, opts ...LoadOption) (*Graph, error) {
+	return c.loadFormat(data, "", xmlFormat, logger, opts)
+}
+
+func (c *Container) XMLFileConfigurationContainer(filename string, logger Logger, opts ...LoadOption) (*Graph, error) {
+	return c.loadFormat(nil, filename, xmlFormat, logger, opts)
+}
+
+// ValidateXML checks an XML configuration without building it, see Validate.
+func (c *Container) ValidateXML(data []byte, opts ...LoadOption) error {
+	return c.validateFormat(data, "", xmlFormat, opts)
+}
+
+func (c *Container) ValidateXMLFile(filename string, opts ...LoadOption) error {
+	return c.validateFormat(nil, filename, xmlFormat, opts)
+}
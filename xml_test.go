@@ -0,0 +1,411 @@
+package summer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestContainer_XMLImport(t *testing.T) {
+	con := new(Container)
+	con.Register(AnswerSpeaker{})
+	con.Register(StructAnswer{})
+	con.Register(StructInlineDewTest{})
+	app, err := con.XMLFileConfigurationContainer("testdata/import/main.xml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Start(context.Background()) != nil {
+		t.Fail()
+	}
+	if app.GetDewByName("list").Value.(*StructInlineDewTest).List[0].Answer() != 666 {
+		t.Fail()
+	}
+	if app.GetDewByName("map").Value.(*StructInlineDewTest).Map["key"].Answer() != 666 {
+		t.Fail()
+	}
+	app.Stop(context.Background())
+}
+
+func TestContainer_XMLImportCycle(t *testing.T) {
+	con := new(Container)
+	app, err := con.XMLFileConfigurationContainer("testdata/cycle/a.xml", nil)
+	if app != nil || err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "import cycle") {
+		t.Fatalf("unexpected error %s", err)
+	}
+}
+
+func TestContainer_XMLImportMissing(t *testing.T) {
+	con := new(Container)
+	config := []byte(`
+<rain>
+<import resource="testdata/missing.xml" />
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil)
+	if app != nil || err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestContainer_XMLImportDuplicate(t *testing.T) {
+	con := new(Container)
+	con.Register(StructAnswer{})
+	app, err := con.XMLFileConfigurationContainer("testdata/duplicate/main.xml", nil)
+	if app != nil || err == nil {
+		t.Fatal("expected an error")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "duplicate/answer.xml") || !strings.Contains(msg, "duplicate/main.xml") {
+		t.Fatalf("error doesn't name both files: %s", msg)
+	}
+}
+
+func TestContainer_XMLProfile(t *testing.T) {
+	con := new(Container)
+	con.Register(StructAnswer{})
+	con.Register(StructInlineTest{})
+	config := []byte(`
+<rain>
+<dew id="test" class="summer.StructAnswer" profile="dev">
+<vapor name="Ans" value="1" />
+</dew>
+<dew id="test" class="summer.StructAnswer" profile="!dev">
+<vapor name="Ans" value="2" />
+</dew>
+<rain profile="prod">
+<dew id="prod" class="summer.StructInlineTest" />
+<dew id="eu" class="summer.StructInlineTest" profile="eu" />
+</rain>
+</rain>
+`)
+	cases := []struct {
+		Options []LoadOption
+		Ans     int
+		Prod    bool
+		EU      bool
+	}{
+		{Options: []LoadOption{WithPropertySources(MapSource{})}, Ans: 2},
+		{Options: []LoadOption{WithProfiles("dev")}, Ans: 1},
+		{Options: []LoadOption{WithProfiles("prod")}, Ans: 2, Prod: true},
+		{Options: []LoadOption{WithProfiles("eu")}, Ans: 2},
+		{Options: []LoadOption{WithProfiles("prod", "eu")}, Ans: 2, Prod: true, EU: true},
+		{Options: []LoadOption{WithPropertySources(MapSource{ProfilesProperty: "dev, prod"})}, Ans: 1, Prod: true},
+	}
+	for i, e := range cases {
+		app, err := con.XMLConfigurationContainer(config, nil, e.Options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if app.GetDewByName("test").Value.(*StructAnswer).Ans != e.Ans {
+			t.Fatalf("case %d: unexpected answer", i)
+		}
+		if (app.GetDewByName("prod") != nil) != e.Prod || (app.GetDewByName("eu") != nil) != e.EU {
+			t.Fatalf("case %d: unexpected dews", i)
+		}
+	}
+}
+
+func TestContainer_XMLProfileImport(t *testing.T) {
+	con := new(Container)
+	con.Register(AnswerSpeaker{})
+	con.Register(StructAnswer{})
+	config := []byte(`
+<rain>
+<rain profile="dev">
+<import resource="testdata/import/answer.yaml" />
+</rain>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithProfiles("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.GetDewByName("test") != nil {
+		t.Fail()
+	}
+	app, err = con.XMLConfigurationContainer(config, nil, WithProfiles("dev"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.GetDewByName("test") == nil {
+		t.Fail()
+	}
+}
+
+func TestContainer_XMLProfileReimport(t *testing.T) {
+	con := new(Container)
+	con.Register(StructAnswer{})
+	config := []byte(`
+<rain>
+<rain profile="dev">
+<import resource="testdata/import/answer.yaml" />
+</rain>
+<rain profile="prod">
+<import resource="testdata/import/answer.yaml" />
+</rain>
+</rain>
+`)
+	cases := []struct {
+		Profiles []string
+		Found    bool
+	}{
+		{[]string{"dev"}, true},
+		{[]string{"prod"}, true},
+		{[]string{"dev", "prod"}, true},
+		{[]string{"eu"}, false},
+	}
+	for _, e := range cases {
+		app, err := con.XMLConfigurationContainer(config, nil, WithProfiles(e.Profiles...))
+		if err != nil {
+			t.Fatalf("profiles %v: %v", e.Profiles, err)
+		}
+		if (app.GetDewByName("test") != nil) != e.Found {
+			t.Fatalf("profiles %v: unexpected dews", e.Profiles)
+		}
+	}
+}
+
+var privateStarted int
+
+type PrivateCounter struct {
+	started bool
+}
+
+func (p *PrivateCounter) Start(ctx context.Context) error {
+	p.started = true
+	privateStarted++
+	return nil
+}
+
+func (p *PrivateCounter) Stop(ctx context.Context) error {
+	p.started = false
+	privateStarted--
+	return nil
+}
+
+type PrivateUser struct {
+	Shared  *PrivateCounter `vapor:"auto"`
+	Private *PrivateCounter
+}
+
+func TestContainer_XMLPrivate(t *testing.T) {
+	con := new(Container)
+	con.Register(PrivateUser{})
+	config := []byte(`
+<rain>
+<dew id="a" class="summer.PrivateUser">
+	<vapor name="Private" private="true" />
+</dew>
+<dew id="b" class="summer.PrivateUser">
+	<vapor name="Private" private="true" />
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := app.GetDewByName("a").Value.(*PrivateUser)
+	b := app.GetDewByName("b").Value.(*PrivateUser)
+	if a.Shared == nil || a.Shared != b.Shared {
+		t.Fatal("auto vapors aren't shared")
+	}
+	if a.Private == nil || b.Private == nil || a.Private == b.Private || a.Private == a.Shared {
+		t.Fatal("private vapors are shared")
+	}
+	privateStarted = 0
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if privateStarted != 3 || !a.Private.started || !b.Private.started {
+		t.Fatalf("expected 3 started counters, got %d", privateStarted)
+	}
+	app.Stop(context.Background())
+	if privateStarted != 0 {
+		t.Fatalf("expected every counter to be stopped, got %d", privateStarted)
+	}
+}
+
+type OptionalUser struct {
+	Metrics *StructAnswer
+	Answer  Answerable
+	Named   Answerable
+}
+
+func TestContainer_XMLOptional(t *testing.T) {
+	con := new(Container)
+	con.Register(OptionalUser{})
+	config := []byte(`
+<rain>
+<dew id="user" class="summer.OptionalUser">
+	<vapor name="Metrics" dew="metrics" optional="true" />
+	<vapor name="Answer" optional="true" />
+	<vapor name="Named" dew="metrics" optional="true" />
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := app.GetDewByName("user").Value.(*OptionalUser)
+	if user.Metrics != nil || user.Answer != nil || user.Named != nil {
+		t.Fatal("optional vapors were set")
+	}
+}
+
+type HandlerA struct{}
+
+func (h *HandlerA) Answer() int { return 1 }
+
+type HandlerB struct{}
+
+func (h *HandlerB) Answer() int { return 2 }
+
+type HandlerC struct{}
+
+func (h *HandlerC) Answer() int { return 3 }
+
+type HandlerUser struct {
+	Handlers []Answerable
+	ByName   map[string]Answerable `vapor:"auto"`
+}
+
+func TestContainer_XMLMultiBinding(t *testing.T) {
+	con := new(Container)
+	con.Register(HandlerA{})
+	con.Register(HandlerB{})
+	con.Register(HandlerC{})
+	con.Register(HandlerUser{})
+	config := []byte(`
+<rain>
+<dew class="summer.HandlerA" order="2" />
+<dew class="summer.HandlerB" order="1" />
+<dew id="c" class="summer.HandlerC" />
+<dew id="user" class="summer.HandlerUser">
+	<vapor name="Handlers" auto="true" named="true" />
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := app.GetDewByName("user").Value.(*HandlerUser)
+	var answers []int
+	for _, h := range user.Handlers {
+		answers = append(answers, h.Answer())
+	}
+	if fmt.Sprint(answers) != "[3 2 1]" {
+		t.Fatalf("unexpected order %v", answers)
+	}
+	if len(user.ByName) != 1 || user.ByName["c"].Answer() != 3 {
+		t.Fatalf("unexpected %v", user.ByName)
+	}
+}
+
+type QualifiedUser struct {
+	Answer  Answerable
+	Reader  Answerable `vapor:"qualifier=read"`
+	Primary *HandlerA  `vapor:"auto"`
+}
+
+func TestContainer_XMLPrimaryQualifier(t *testing.T) {
+	con := new(Container)
+	con.Register(HandlerA{})
+	con.Register(HandlerB{})
+	con.Register(HandlerC{})
+	con.Register(QualifiedUser{})
+	config := []byte(`
+<rain>
+<dew class="summer.HandlerA" />
+<dew class="summer.HandlerB" primary="true" />
+<dew id="replica" class="summer.HandlerC" qualifier="read, replica" />
+<dew id="user" class="summer.QualifiedUser">
+	<vapor name="Answer" auto="true" />
+</dew>
+</rain>
+`)
+	app, err := con.XMLConfigurationContainer(config, nil, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := app.GetDewByName("user").Value.(*QualifiedUser)
+	if user.Answer.Answer() != 2 || user.Reader.Answer() != 3 || user.Primary == nil {
+		t.Fatalf("unexpected %+v", user)
+	}
+}
+
+func TestContainer_XMLQualifierVapor(t *testing.T) {
+	con := new(Container)
+	con.Register(HandlerA{})
+	con.Register(HandlerC{})
+	con.Register(QualifiedUser{})
+	config := []byte(`
+<rain>
+<dew class="summer.HandlerA" />
+<dew id="replica" class="summer.HandlerC" qualifier="read" />
+<dew id="user" class="summer.QualifiedUser">
+	<vapor name="Answer" qualifier="read" />
+</dew>
+</rain>
+`)
+	if err := con.ValidateXML(config); err != nil {
+		t.Fatal(err)
+	}
+	app, err := con.XMLConfigurationContainer(config, nil, WithValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer := app.GetDewByName("user").Value.(*QualifiedUser).Answer.Answer(); answer != 3 {
+		t.Fatalf("expected the qualified answer but got %d", answer)
+	}
+}
+
+type QualifiedPointerUser struct {
+	Reader *HandlerA `vapor:"qualifier=read"`
+}
+
+func TestContainer_XMLQualifierAmbiguous(t *testing.T) {
+	con := new(Container)
+	con.Register(HandlerA{})
+	con.Register(QualifiedPointerUser{})
+	config := []byte(`
+<rain>
+<dew id="a" class="summer.HandlerA" qualifier="read" />
+<dew id="b" class="summer.HandlerA" qualifier="read" />
+<dew id="user" class="summer.QualifiedPointerUser" />
+</rain>
+`)
+	const msg = "found two assignable values for field Reader"
+	for _, opts := range [][]LoadOption{nil, {WithValidation()}} {
+		_, err := con.XMLConfigurationContainer(config, nil, opts...)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+}
+
+func TestContainer_XMLQualifierMissing(t *testing.T) {
+	con := new(Container)
+	con.Register(QualifiedUser{})
+	config := []byte(`
+<rain>
+<dew id="user" class="summer.QualifiedUser">
+	<vapor name="Answer" qualifier="write" optional="true" />
+</dew>
+</rain>
+`)
+	err := con.ValidateXML(config)
+	if err == nil || !strings.Contains(err.Error(), "found no assignable value for field Reader") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if strings.Contains(err.Error(), "field Answer") {
+		t.Fatalf("reported the optional field: %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package summer
+
+// YAMLConfigurationContainer builds a Graph from a YAML configuration. The
+// document mirrors the XML one: a top level "dew" list, each dew carrying an
+// id, a class and a "vapor" list.
+func (c *Container) YAMLConfigurationContainer(data []byte, logger Logger, opts ...LoadOption) (*Graph, error) {
+	return c.loadFormat(data, "", yamlFormat, logger, opts)
+}
+
+func (c *Container) YAMLFileConfigurationContainer(filename string, logger Logger, opts ...LoadOption) (*Graph, error) {
+	return c.loadFormat(nil, filename, yamlFormat, logger, opts)
+}
+
+// ValidateYAML checks a YAML configuration without building it, see Validate.
+func (c *Container) ValidateYAML(data []byte, opts ...LoadOption) error {
+	return c.validateFormat(data, "", yamlFormat, opts)
+}
+
+func (c *Container) ValidateYAMLFile(filename string, opts ...LoadOption) error {
+	return c.validateFormat(nil, filename, yamlFormat, opts)
+}
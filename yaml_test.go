@@ -0,0 +1,122 @@
+package summer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContainer_YAMLConfigurationContainer(t *testing.T) {
+	con := new(Container)
+	con.Register(AnswerSpeaker{})
+	con.Register(StructAnswer{})
+	config := []byte(`
+dew:
+  - id: test
+    class: summer.StructAnswer
+    vapor:
+      - name: Ans
+        value: 666
+  - id: checker
+    class: summer.AnswerSpeaker
+    vapor:
+      - name: Answer
+        dew: test
+`)
+	app, err := con.YAMLConfigurationContainer(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Start(context.Background()) != nil {
+		t.Fail()
+	}
+	app.Stop(context.Background())
+}
+
+func TestContainer_YAMLAutoInject(t *testing.T) {
+	con := new(Container)
+	con.Register(AnswerSpeaker{})
+	con.Register(StructAnswer{})
+	config := []byte(`
+dew:
+  - class: summer.StructAnswer
+    vapor:
+      - name: Ans
+        value: 666
+  - id: checker
+    class: summer.AnswerSpeaker
+    vapor:
+      - name: Answer
+        auto: true
+`)
+	app, err := con.YAMLConfigurationContainer(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Start(context.Background()) != nil {
+		t.Fail()
+	}
+	app.Stop(context.Background())
+}
+
+func TestContainer_YAMLInjectList(t *testing.T) {
+	con := new(Container)
+	con.Register(StructInlineTest{})
+	con.Register(StructInlineDewTest{})
+	con.Register(StructAnswer{})
+	config := []byte(`
+dew:
+  - id: test
+    class: summer.StructInlineTest
+    vapor:
+      - name: List
+        vapor:
+          - value: test1
+          - value: test2
+      - name: Array
+        vapor: [{value: 1}, {value: 2}, {value: 3}, {value: 4}, {value: 5}]
+      - name: Map
+        vapor:
+          - {name: key1, value: test1}
+  - id: answer
+    class: summer.StructAnswer
+    vapor:
+      - {name: Ans, value: 2}
+  - id: dews
+    class: summer.StructInlineDewTest
+    vapor:
+      - name: List
+        vapor:
+          - dew: answer
+      - name: Map
+        vapor:
+          - {name: key, dew: answer}
+`)
+	app, err := con.YAMLConfigurationContainer(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := app.GetDewByName("test").Value.(*StructInlineTest)
+	if len(test.List) != 2 || test.List[1] != "test2" {
+		t.Fail()
+	}
+	if test.Array[4] != 5 || test.Map["key1"] != "test1" {
+		t.Fail()
+	}
+	dews := app.GetDewByName("dews").Value.(*StructInlineDewTest)
+	if dews.List[0].Answer() != 2 || dews.Map["key"].Answer() != 2 {
+		t.Fail()
+	}
+}
+
+func TestContainer_YAMLBadYAML(t *testing.T) {
+	con := new(Container)
+	config := []byte(`
+dew:
+  - id: test
+   class: summer.StructAnswer
+`)
+	app, _ := con.YAMLConfigurationContainer(config, nil)
+	if app != nil {
+		t.Fail()
+	}
+}